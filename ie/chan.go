@@ -0,0 +1,245 @@
+package ie
+
+import (
+	"reflect"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// feeds the slices received from ch as chunks, and End when ch is closed.
+// answers Position, ReadAtLeast, and Flush.
+func EnumChan(ch <-chan []byte) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			pos, rest := int64(0), Empty	// cf. Answer
+
+			for it.k != nil {
+				if it.err != nil {
+					var ok bool
+					if it, rest, ok = Answer(it, pos, rest); !ok {
+						return it
+					}
+					continue
+				}
+
+				bs, ok := <-ch
+				if !ok {
+					if it, rest = it.k(Empty); it.IsCont() {
+						it, _ = it.k(End)
+						return it
+					}
+					continue
+				}
+				pos += int64(len(bs))
+				it, rest = it.k(Chunk(bs))
+			}
+			return it
+		})
+	}
+}
+
+// an iteratee that sends every element of its input to ch and closes ch
+// at the end of input.
+func ChanSink(ch chan<- interface{}) (this Iteratee) {
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if s.IsEnd() {
+			close(ch)
+			if err := s.Err(); err != nil {
+				return Fail(err), s
+			}
+			return Done(nil), s
+		}
+		for s != Empty {
+			var x interface{}
+			x, s = s.Take1()
+			ch <- x
+		}
+		return this, s
+	})
+	return
+}
+
+// run the enumeratee ee in its own goroutine, passing it input through a
+// queue of up to 'buf' chunks. the goroutine is started when the first chunk
+// arrives and exits when ee finishes, at End, or when fed Empty. the first
+// chunk, e.g. what a previous stage left, is processed before Async returns.
+//
+// with buf == 0, every chunk is handed over and processed before the next is
+// accepted, so Async(ee, 0) behaves like ee, only on another stack.
+//
+// with buf > 0, chunks are accepted while ee is still busy; they are copied
+// on the way, so ee can keep up with an enumerator that reuses its buffer (cf.
+// Options). when ee finishes or stops on a request, this is noticed with the
+// next chunk fed, and the input it did not consume, including any chunks
+// still queued, is returned then as leftover, joined into one chunk (which
+// bit streams do not support). feeding Empty waits for ee to work through
+// the queue, which enumerators do before they return. should ee finish while
+// chunks are queued and End follows before this is noticed, those chunks are
+// lost: nothing follows End.
+//
+// a request from ee is passed on; resuming the iteratee goes back to running
+// ee asynchronously.
+//
+// NB: enumerators feed Empty before they return (cf. Control), so ee is
+//     caught up with and its goroutine gone by then. an iteratee dropped
+//     in the middle of input, e.g. on a cancelled context, leaves it
+//     blocked; finish it with End (cf. Run), or feed it Empty.
+func Async(ee Enumeratee, buf int) Enumeratee {
+	if buf < 0 {
+		buf = 0
+	}
+	return func(inner Iteratee) Iteratee {
+		return goasync(ee(inner), buf)
+	}
+}
+
+// what the goroutine reports: the iteratee and what it left of its input
+type asyncResult struct {
+	it   Iteratee
+	rest Stream
+}
+
+func (r asyncResult) final() bool {
+	return !r.it.IsCont() || r.rest.IsEnd()
+}
+
+// run 'it' in a new goroutine, once there is input
+func goasync(it Iteratee, buf int) (this Iteratee) {
+	if !it.IsCont() {
+		return unasync(it, buf)
+	}
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if s == Empty {
+			return this, s
+		}
+		in := make(chan Stream, buf)
+		done := make(chan asyncResult, 1)
+		go runasync(it, in, done, buf == 0)
+		in <- s
+		if r := <-done; r.final() {
+			return finish(r, in, Empty, buf)
+		}
+		return async(in, done, buf), Empty
+	})
+	return
+}
+
+// feed 'it' from 'in' until it is no longer continuing, has seen the end of
+// input, or gets Empty, and report it on 'done'. also report after the first
+// chunk and, with ack, after every one.
+func runasync(it Iteratee, in <-chan Stream, done chan<- asyncResult, ack bool) {
+	first := true
+	for s := range in {
+		last := s == Empty
+		it, s = it.Feed(s)
+		r := asyncResult{it, s}
+		last = last || r.final()
+		if ack || first || last {
+			done <- r
+		}
+		if last {
+			return
+		}
+		first = false
+	}
+}
+
+func async(in chan Stream, done <-chan asyncResult, buf int) (this Iteratee) {
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if buf == 0 {
+			in <- s
+			if r := <-done; r.final() || s == Empty {
+				return finish(r, in, Empty, buf)
+			}
+			return this, Empty
+		}
+
+		if s == Empty || s.IsEnd() {
+			// wait for ee to work through the queue
+			select {
+			case r := <-done:
+				return finish(r, in, s, buf)
+			case in <- s:
+				return finish(<-done, in, Empty, buf)
+			}
+		}
+		select {
+		case r := <-done:
+			return finish(r, in, s, buf)
+		case in <- s.clone():
+			return this, Empty
+		}
+	})
+	return
+}
+
+// the goroutine has reported r and exited. collect the input it did not get
+// to: what it left of its last chunk, the chunks still queued, and s.
+func finish(r asyncResult, in chan Stream, s Stream, buf int) (Iteratee, Stream) {
+	close(in)
+	chunks := []Stream{r.rest}
+	for c := range in {
+		chunks = append(chunks, c)
+	}
+	return unasync(r.it, buf), join(append(chunks, s))
+}
+
+// 'it' as reported by the goroutine. if it is continuing or stopped on a
+// request, feeding or resuming it runs it asynchronously again.
+func unasync(it Iteratee, buf int) Iteratee {
+	switch {
+	case it.IsCont():
+		return goasync(it, buf)
+	case it.Request() != nil:
+		return Stop(it.err, func(s Stream) (Iteratee, Stream) {
+			return goasync(Cont(it.k), buf).k(s)
+		})
+	}
+	return it
+}
+
+// the data of the given chunks in one. if there is an End, that instead.
+func join(chunks []Stream) Stream {
+	var data []Stream
+	for _, c := range chunks {
+		if c.IsEnd() {
+			return c
+		}
+		if c != Empty {
+			data = append(data, c)
+		}
+	}
+	switch len(data) {
+	case 0:
+		return Empty
+	case 1:
+		return data[0]
+	}
+
+	n := 0
+	for _, c := range data {
+		if c.isBit {
+			panic("Async: cannot join bit stream chunks")
+		}
+		n += c.Len()
+	}
+	v := reflect.MakeSlice(reflect.TypeOf(data[0].slice), 0, n)
+	for _, c := range data {
+		v = reflect.AppendSlice(v, reflect.ValueOf(c.slice))
+	}
+	return Chunk(v.Interface())
+}
+
+// a copy of s that does not share its underlying slice
+func (s *Stream) clone() Stream {
+	if s.slice == nil {
+		return *s
+	}
+	v := reflect.ValueOf(s.slice)
+	c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(c, v)
+	t := *s
+	t.slice = c.Interface()
+	return t
+}
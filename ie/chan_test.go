@@ -0,0 +1,214 @@
+package ie
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"strings"
+	"testing/iotest"
+
+	"github.com/pesco/go/monad"
+)
+
+
+func TestEnumChan(t *testing.T) {
+	ch := make(chan []byte)
+	go func() {
+		for _, s := range []string{"hal", "lo ", "", "welt"} {
+			ch <- []byte(s)
+		}
+		close(ch)
+	}()
+
+	it := Seq(String("hallo"), Byte(' '), Tell, String("welt"), EndOfInput)
+	it = EnumChan(ch)(it).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+		return
+	}
+	r := it.Result().([]interface{})
+	if r[2].(int64) != 6 || r[3].(string) != "welt" {
+		t.Error("wrong result; got:", r)
+	}
+}
+
+func TestChanSink(t *testing.T) {
+	ch := make(chan interface{}, 16)
+	enum := EnumString("hallo")
+	enum(ChanSink(ch)).(monad.IO)().(Iteratee).Run()
+
+	var result []byte
+	for x := range ch {
+		result = append(result, x.(byte))
+	}
+	if string(result) != "hallo" {
+		t.Error("wrong result; got:", result)
+	}
+}
+
+func TestAsync(t *testing.T) {
+	// a slow reader that reuses its one-byte buffer
+	slow := ReadWith(iotest.OneByteReader(strings.NewReader("abc\ndef\n")),
+	                 Options{1, true})
+
+	enum := slow.Pipe(Async(Pass, 2))
+	it := enum(String("abc\ndef\n")).(monad.IO)().(Iteratee)
+	result := it.Run()
+	if result.(string) != "abc\ndef\n" {
+		t.Error("wrong result; got:", result)
+	}
+
+	// enumeratee finishing early
+	ch := make(chan interface{}, 16)
+	enum = EnumString("abc\ndef\n").Pipe(Async(BreakAfter([]byte("\n")), 1))
+	enum(ChanSink(ch)).(monad.IO)().(Iteratee).Run()
+
+	var line []byte
+	for x := range ch {
+		line = append(line, x.(byte))
+	}
+	if string(line) != "abc\n" {
+		t.Errorf("wrong result; got %q", line)
+	}
+}
+
+// what an iteratee and its leftover look like after a feed
+func describe(it Iteratee, s Stream) string {
+	rest := "END"
+	if !s.IsEnd() {
+		rest = fmt.Sprintf("%q", s.Slice())
+	}
+	return fmt.Sprint(it.IsDone(), it.IsStop(), rest)
+}
+
+func TestAsyncDropIn(t *testing.T) {
+	ee := BreakAfter([]byte("\n"))
+	inputs := [][]Stream{
+		{Chunk("ab\ncd"), Chunk("ef"), End},
+		{Chunk("ab"), Chunk("\n"), End},
+		{Chunk("ab"), End},
+	}
+	for _, input := range inputs {
+		sync := ee(Many([]byte(nil), Any))
+		async := Async(ee, 0)(Many([]byte(nil), Any))
+		for _, s := range input {
+			var a, b Stream
+			sync, a = sync.Feed(s)
+			async, b = async.Feed(s)
+			if describe(sync, a) != describe(async, b) {
+				t.Errorf("differs from ee; want %s, got %s",
+				         describe(sync, a), describe(async, b))
+			}
+			if sync.IsDone() {
+				break
+			}
+		}
+	}
+}
+
+func TestAsyncLeftover(t *testing.T) {
+	input := []Stream{Chunk("ab\ncd"), Chunk("ef"), Chunk("gh"), Empty, End}
+	parse := func(ee Enumeratee) string {
+		line := ee(Many([]byte(nil), Any)).Fuse()
+		it := Seq(line, Many([]byte(nil), Any))
+		for _, s := range input {
+			it, _ = it.Feed(s)
+		}
+		if !it.IsDone() {
+			return fmt.Sprint("not done: ", it.Err())
+		}
+		return fmt.Sprintf("%q", it.Result())
+	}
+
+	want := parse(BreakAfter([]byte("\n")))
+	for _, buf := range []int{0, 1, 4} {
+		got := parse(Async(BreakAfter([]byte("\n")), buf))
+		if got != want {
+			t.Errorf("buf %d: want %s, got %s", buf, want, got)
+		}
+	}
+}
+
+func TestAsyncEnd(t *testing.T) {
+	// ee finishes on the first chunk, End follows the queued ones
+	for _, buf := range []int{0, 1, 4} {
+		ee := Async(BreakAfter([]byte("\n")), buf)
+		it := Choice(ee(Many([]byte(nil), Any)).Fuse())
+		for _, s := range []Stream{Chunk("ab\n"), Chunk("cd"), End} {
+			var rest Stream
+			if it, rest = it.Feed(s); s.IsEnd() && !rest.IsEnd() {
+				t.Errorf("buf %d: End followed by %q", buf, rest.Slice())
+			}
+		}
+		if !it.IsDone() {
+			t.Errorf("buf %d: should have succeeded; err: %v", buf, it.Err())
+		}
+	}
+}
+
+func TestAsyncRequest(t *testing.T) {
+	data := []byte("abcdefgh")
+	enums := map[string]func() Enumerator{
+		"EnumSlice": func() Enumerator { return EnumChunksOf(2, data) },
+		"EnumBytes": func() Enumerator { return EnumBytes(data) },
+		"Read": func() Enumerator {
+			return ReadWith(strings.NewReader(string(data)), Options{2, true})
+		},
+		"ReadAt": func() Enumerator {
+			return ReadAt(strings.NewReader(string(data)), int64(len(data)))
+		},
+		"EnumChan": func() Enumerator {
+			ch := make(chan []byte, 4)
+			for i := 0; i < len(data); i += 2 {
+				ch <- data[i:i+2]
+			}
+			close(ch)
+			return EnumChan(ch)
+		},
+	}
+	its := []Iteratee{
+		Seq(String("ab"), Tell, String("cdefgh")),
+		Seq(String("abcdefgh"), Tell),	// request at the very end
+	}
+
+	for name, enum := range enums {
+		for _, it := range its {
+			want := fmt.Sprint(enum().Pipe(Pass).Run(it)().Get())
+			for _, buf := range []int{0, 1, 4} {
+				e := enum().Pipe(Async(Pass, buf))
+				got := fmt.Sprint(e.Run(it)().Get())
+				if got != want {
+					t.Errorf("%s, buf %d: want %s, got %s",
+					         name, buf, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestAsyncRepeat(t *testing.T) {
+	enum := EnumSlice([][]byte{[]byte("ab\nc"), []byte("d\nef")})
+	run := func(ee Enumeratee) string {
+		x, err := enum.Pipe(Repeat(ee)).Run(Many([]byte(nil), Any))().Get()
+		return fmt.Sprintf("%q %v", x, err)
+	}
+
+	want := run(BreakAfter([]byte("\n")))
+	for _, buf := range []int{0, 1, 4} {
+		if got := run(Async(BreakAfter([]byte("\n")), buf)); got != want {
+			t.Errorf("buf %d: want %s, got %s", buf, want, got)
+		}
+	}
+}
+
+func TestAsyncGoroutines(t *testing.T) {
+	// Read returns at end-of-file, without End
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		enum := ReadWith(strings.NewReader("abcdefgh"), Options{2, true})
+		enum.Pipe(Async(Pass, 2))(Many([]byte(nil), Any)).(monad.IO)()
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left running", n - before)
+	}
+}
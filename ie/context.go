@@ -0,0 +1,39 @@
+package ie
+
+import (
+	"context"
+	"io"
+)
+
+
+// cancellation: when ctx is done, the iteratee is returned stopped with
+// ctx.Err() and its continuation intact, so it can be resumed elsewhere.
+// NB: ctx is checked between chunks; a Read that blocks is not interrupted.
+
+// like Read, but stops when ctx is done
+func ReadCtx(ctx context.Context, r io.Reader) Enumerator {
+	return read(ctx, r, false, Options{DefaultBufSize, true})
+}
+
+// like SeekableRead, but stops when ctx is done
+func SeekableReadCtx(ctx context.Context, r io.ReadSeeker) Enumerator {
+	return read(ctx, r, true, Options{DefaultBufSize, true})
+}
+
+// make 'it' stop with ctx.Err() on the next input after ctx is done,
+// regardless of the enumerator. this allows cancelling a running iteratee,
+// e.g. from another goroutine.
+func WithContext(ctx context.Context, it Iteratee) Iteratee {
+	if it.k == nil {
+		return it
+	}
+	var k func(s Stream) (Iteratee, Stream)
+	k = func(s Stream) (Iteratee, Stream) {
+		if err := ctx.Err(); err != nil {
+			return Stop(err, k), s
+		}
+		it, s := it.k(s)
+		return WithContext(ctx, it), s
+	}
+	return Iteratee{nil, k, it.err}
+}
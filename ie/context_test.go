@@ -0,0 +1,57 @@
+package ie
+
+import (
+	"testing"
+	"context"
+	"io"
+	"strings"
+	"testing/iotest"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// calls f after every Read
+type hookReader struct {
+	r io.Reader
+	f func()
+}
+func (h hookReader) Read(p []byte) (int, error) {
+	defer h.f()
+	return h.r.Read(p)
+}
+
+func TestReadCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := hookReader{iotest.OneByteReader(strings.NewReader("hallo welt")), cancel}
+
+	it := ReadCtx(ctx, r)(String("hallo welt")).(monad.IO)().(Iteratee)
+	if it.Err() != context.Canceled {
+		t.Error("should have been cancelled; err:", it.Err())
+		return
+	}
+
+	// the iteratee can go on with another enumerator
+	it, _ = it.K(Empty)
+	it = Read(strings.NewReader("allo welt"))(it).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan []byte)
+	go func() {
+		ch <- []byte("hallo ")
+		cancel()
+		ch <- []byte("welt")
+		close(ch)
+	}()
+
+	it := WithContext(ctx, String("hallo welt"))
+	it = EnumChan(ch)(it).(monad.IO)().(Iteratee)
+	if it.Err() != context.Canceled {
+		t.Error("should have been cancelled; err:", it.Err())
+	}
+}
@@ -22,17 +22,26 @@ func (e Enumeratee) ToEnumerator() Enumerator {
 	}
 }
 
-// enumeratee that passes its input as-is to the inner iteratee
+// enumeratee that passes its input as-is to the inner iteratee.
+// requests from the inner iteratee are passed on to the enumerator.
 var Pass Enumeratee = pass
 func pass(it Iteratee) Iteratee {
 	return Cont(func(s Stream) (Iteratee, Stream) {
-		it, s := it.Feed(s)
-		if it.k == nil || it.err != nil {
-			return Done(it), s
-		}
-		return pass(it), s
+		return passon(it.Feed(s))
 	})
 }
+func passon(it Iteratee, s Stream) (Iteratee, Stream) {
+	if req := it.Request(); req != nil {
+		k := func(s Stream) (Iteratee, Stream) {
+			return passon(it.k(s))
+		}
+		return Stop(req, k), s
+	}
+	if it.k == nil || it.err != nil {
+		return Done(it), s
+	}
+	return pass(it), s
+}
 
 // attach an enumeratee to the output of an enumerator
 func (e Enumerator) Pipe(ee Enumeratee) Enumerator {
@@ -68,10 +77,13 @@ func Prefix(it Iteratee, ee Enumeratee) Enumeratee {
 func BreakAfter(sep []byte) Enumeratee {
 	return func(inner Iteratee) (this Iteratee) {
 		this = Cont(func(s Stream) (Iteratee, Stream) {
-			if s == End {
+			if s.IsEnd() {
 				inner, _ = inner.Feed(s)
 				return Done(inner), s
 			}
+			if s == Empty {
+				return this, s
+			}
 			bs := s.Slice().([]byte)
 			idx := bytes.Index(bs, sep)
 			if idx == -1 {
@@ -86,13 +98,19 @@ func BreakAfter(sep []byte) Enumeratee {
 	}
 }
 
-// enumeratee equivalent of Many
+// enumeratee equivalent of Many. at the end of input, a gets one last round,
+// e.g. to flush, but is not repeated further.
 func Repeat(a Enumeratee) (this Enumeratee) {
 	this = func(it Iteratee) Iteratee {
 		f := func(it_ interface{}) Iteratee {
 			return this(it_.(Iteratee))
 		}
-		return OChoice(a(it).Bind(f), Done(it))
+		return Cont(func(s Stream) (Iteratee, Stream) {
+			if s.IsEnd() {
+				return OChoice(a(it), Done(it)).Feed(s)
+			}
+			return OChoice(a(it).Bind(f), Done(it)).Feed(s)
+		})
 	}
 	return
 }
@@ -2,6 +2,7 @@ package ie
 
 import (
 	"testing"
+	"bytes"
 	"os"
 	"strings"
 	
@@ -84,3 +85,24 @@ func TestRepeat1(t *testing.T) {
 		t.Error("wrong result; got:", result)
 	}
 }
+
+func TestPassRequests(t *testing.T) {
+	enum := SeekableRead(bytes.NewReader([]byte("0123456789"))).Pipe(Pass)
+	it := enum(Raise(Seek{4}).Then(Uint(BE, 2))).(monad.IO)().(Iteratee)
+
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	} else if it.Result().(uint64) != 0x3435 {
+		t.Errorf("wrong result; got %#v", it.Result())
+	}
+}
+
+func TestRepeatEnd(t *testing.T) {
+	// BreakAfter succeeds on End, Repeat must not loop on it
+	enum := EnumSlice([][]byte{[]byte("ab\nc"), []byte("d\nef")})
+	it := enum.Pipe(Repeat(BreakAfter([]byte("\n"))))
+	x, err := it.Run(Many([]byte(nil), Any))().Get()
+	if err != nil || string(x.([]byte)) != "ab\ncd\nef" {
+		t.Errorf("wrong result; got %q, %v", x, err)
+	}
+}
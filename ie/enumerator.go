@@ -1,6 +1,8 @@
 package ie
 
 import (
+	"context"
+	"errors"
 	"io"
 	"fmt"
 
@@ -10,15 +12,149 @@ import (
 type Enumerator func(Iteratee) monad.Monad
 	// the returned Monad yields an Iteratee
 
+var errUnfinished = errors.New("iteratee continues after end of input")
+
+// run e on 'it' to the end as a single action that can fail: feeds End
+// afterwards and yields the iteratee's result, or its error if it did not
+// finish. panics in the enumerator or iteratee are caught as with monad.Try.
+func (e Enumerator) Run(it Iteratee) monad.IOResult {
+	return func() monad.Result {
+		r := monad.Try(func() interface{} {
+			it := e(it).(monad.IO)().(Iteratee)
+			it, _ = it.Feed(End)
+			return it
+		})()
+		x, err := r.Get()
+		if err != nil {
+			return r
+		}
+		it := x.(Iteratee)
+		if it.k != nil {
+			if it.err == nil {
+				return monad.Err(errUnfinished)
+			}
+			return monad.Err(it.err)
+		}
+		return monad.Ok(it.result)
+	}
+}
+
+// feeds s as a single chunk. answers the same requests as EnumBytes.
 func EnumString(s string) Enumerator {
+	return EnumBytes([]byte(s))
+}
+
+// feeds bs as a single chunk. answers Seek, SeekRel, Position, ReadAtLeast,
+// and Flush, like SeekableRead. after a seek, the remainder of bs from the new
+// position is fed as another chunk. a seek before the start feeds
+// EndErr(ErrSeekBeforeStart).
+func EnumBytes(bs []byte) Enumerator {
 	return func(it Iteratee) monad.Monad {
 		return monad.IO(func() interface{} {
-			it, _ = it.Feed(Chunk([]byte(s)))
-			return it
+			end := int64(len(bs))
+			it, rest := it.Feed(Chunk(bs))
+			for {
+				if it.IsCont() {
+					if it, rest = it.k(Empty); it.IsCont() {
+						return it
+					}
+				}
+				if it.Request() == nil {
+					return it
+				}
+				var where int64
+
+				switch req := it.err.(type) {
+				case Seek:
+					where = req.Offset
+					if where < 0 {
+						where += end
+					}
+				case SeekRel:
+					where = end - int64(rest.Len()) + req.Offset
+				default:
+					var ok bool
+					if it, rest, ok = Answer(it, end, rest); !ok {
+						return it
+					}
+					continue
+				}
+
+				if where < 0 {
+					it, _ = it.k(EndErr(ErrSeekBeforeStart))
+					return it
+				}
+				if where > end {
+					where = end
+				}
+				it, rest = it.k(Chunk(bs[where:]))
+			}
 		})
 	}
 }
 
+// control requests...
+
+// an iteratee sends a request to its enumerator by stopping with it as the
+// error, e.g. Raise(Seek{0}). an enumerator answers the requests it supports
+// and resumes the iteratee. any others, it must pass on by returning the
+// iteratee as is, still stopped, so that the next enumerator (cf. Append) or
+// the caller gets a chance to answer them.
+//
+// user-defined requests are any types with the marker method Control().
+// user-defined enumerators can leave the common ones to Answer.
+//
+// before an enumerator returns for want of input, it feeds Empty and answers
+// what that brings up. to a synchronous iteratee this makes no difference,
+// but a stage running behind (cf. Async) catches up on it: requests it makes
+// at the tail of the input still reach the enumerator, and input it leaves
+// is returned before End can follow.
+type Control interface {
+	error
+	Control()
+}
+
+// return the request an iteratee has stopped with, nil if none
+func (it Iteratee) Request() Control {
+	if it.k == nil {
+		return nil
+	}
+	req, _ := it.err.(Control)
+	return req
+}
+
+// answer the requests that need nothing but the position in the stream:
+// Position, ReadAtLeast (taken as a mere hint), and Flush. pos is the position
+// after the last chunk fed to 'it', rest what it left of that chunk. if 'it'
+// is stopped on one of these, it is resumed with rest and the outcome returned
+// with true. otherwise, 'it' and rest are returned as they are with false, for
+// the enumerator to answer its own requests or pass them on:
+//
+//	for it.Request() != nil {
+//		switch req := it.Request().(type) {
+//		case MyRequest:
+//			// ...
+//			it, rest = it.K(rest)
+//		default:
+//			var ok bool
+//			if it, rest, ok = ie.Answer(it, pos, rest); !ok {
+//				return it
+//			}
+//		}
+//	}
+func Answer(it Iteratee, pos int64, rest Stream) (Iteratee, Stream, bool) {
+	switch req := it.Request().(type) {
+	case Position:
+		*req.Pos = pos - int64(rest.Len())
+	case ReadAtLeast, Flush:
+		// nothing held back, chunks are as they come
+	default:
+		return it, rest, false
+	}
+	it, rest = it.k(rest)
+	return it, rest, true
+}
+
 // stop an iteratee with this message to signal a supporting enumerator
 // to seek to the given position in the stream, e.g. a file. a negative offset
 // counts from the end of the file, where -1 indicates the last byte.
@@ -29,76 +165,271 @@ type Seek struct {
 func (sk Seek) Error() string {
 	return fmt.Sprintf("tried to seek (to position %#x)", sk.Offset)
 }
+func (Seek) Control() {}
+
 type SeekRel struct {
 	Offset int64
 }
 func (sk SeekRel) Error() string {
 	return fmt.Sprintf("tried to seek (by %v bytes)", sk.Offset)
 }
+func (SeekRel) Control() {}
+
+// fed with EndErr by the in-memory enumerators and ReadAt on a seek before the
+// start of their input, as SeekableRead feeds the error from Seek
+var ErrSeekBeforeStart = errors.New("seek before the start of input")
+
+// ask the enumerator for the current position in the stream. a supporting
+// enumerator stores it in *Pos before resuming the iteratee.
+type Position struct {
+	Pos *int64
+}
+func (Position) Error() string {
+	return "tried to get stream position"
+}
+func (Position) Control() {}
+
+// return the current position in the stream (as int64), as answered by the
+// enumerator. cf. Position
+var Tell Iteratee = Cont(k_tell)
+func k_tell(s Stream) (Iteratee, Stream) {
+	pos := new(int64)
+	k := func(s Stream) (Iteratee, Stream) {return Done(*pos), s}
+	return Stop(Position{pos}, k), s
+}
+
+// a hint that the iteratee would like chunks of at least N bytes. there is no
+// guarantee that the enumerator can or will deliver them.
+type ReadAtLeast struct {
+	N int
+}
+func (r ReadAtLeast) Error() string {
+	return fmt.Sprintf("asked for at least %d bytes per chunk", r.N)
+}
+func (ReadAtLeast) Control() {}
+
+// ask the enumerator to deliver any input it has been holding back.
+type Flush struct{}
+func (Flush) Error() string {return "asked for a flush"}
+func (Flush) Control() {}
+
+
+// readers...
+
+const DefaultBufSize = 1024
+
+// options for ReadWith and SeekableReadWith
+type Options struct {
+	BufSize int		// size of the chunks to read; <= 0 means DefaultBufSize
+	Reuse   bool	// read every chunk into the same buffer?
+}
+
+// chunk lifetime: with Reuse, every chunk is read into the same buffer, so
+// its contents are only valid until the iteratee returns from processing it.
+// an iteratee that wants to keep any part of it, must copy. without Reuse,
+// every chunk is a fresh buffer that the iteratee is free to keep.
 
+// answers Position, ReadAtLeast, and Flush. positions count from where r was
+// when the enumerator started. reuses a buffer of DefaultBufSize.
+//
+// on end-of-file, the iteratee is returned without End, so it can go on with
+// another enumerator. on any other read error, it is fed EndErr with the
+// error, which the primitive parsers fail with; if it is already done or
+// stopped on a request, it is returned unchanged. the same goes for the other
+// readers, and for a failed Seek, which is answered with EndErr.
 func Read(r io.Reader) Enumerator {
-	return read(r, false)
+	return ReadWith(r, Options{DefaultBufSize, true})
 }
+
+// answers Seek, SeekRel, Position, ReadAtLeast, and Flush.
+// reuses a buffer of DefaultBufSize.
 func SeekableRead(r io.ReadSeeker) Enumerator {
-	return read(r, true)
+	return SeekableReadWith(r, Options{DefaultBufSize, true})
+}
+
+// like Read, but with the given buffer options
+func ReadWith(r io.Reader, opts Options) Enumerator {
+	return read(context.Background(), r, false, opts)
+}
+
+// like SeekableRead, but with the given buffer options
+func SeekableReadWith(r io.ReadSeeker, opts Options) Enumerator {
+	return read(context.Background(), r, true, opts)
 }
-func read(r io.Reader, seekable bool) Enumerator {
-	buf := make([]byte, 1024)
 
+func read(ctx context.Context, r io.Reader, seekable bool,
+          opts Options) Enumerator {
 	return func(it Iteratee) monad.Monad {
 		return monad.IO(func() interface{} {
+			size := opts.BufSize	// may grow on ReadAtLeast
+			if size <= 0 {
+				size = DefaultBufSize
+			}
+			var buf []byte
+			pos, rest := int64(0), Empty	// cf. Answer
+			if seekable {
+				pos, _ = r.(io.ReadSeeker).Seek(0, io.SeekCurrent)
+			}
+
 			for it.k != nil {
 				if it.err != nil {
-					// Iteratee has stopped - end here or seek if supported
+					// Iteratee has stopped - end here or answer if supported
 					seek := false
 					where := int64(0)
 					whence := io.SeekStart
 
-					switch sk := it.err.(type) {
+					switch req := it.err.(type) {
 					case Seek:
 						seek = true
-						where = sk.Offset
+						where = req.Offset
 						if where < 0 {
 							whence = io.SeekEnd
 						}
 					case SeekRel:
+						// relative to the iteratee's position, not ours
 						seek = true
-						where = sk.Offset
+						where = req.Offset - int64(rest.Len())
 						whence = io.SeekCurrent
+					case ReadAtLeast:
+						if req.N > size {
+							size = req.N
+						}
 					}
 
-					if seek && seekable {
-						_, err := r.(io.ReadSeeker).Seek(where, whence)
-						if err != nil {
-							it, _ = it.Feed(End)	// XXX it.Feed(End(err))
+					if !seek {
+						var ok bool
+						if it, rest, ok = Answer(it, pos, rest); !ok {
 							return it
 						}
-					} else {
+						continue
+					}
+					if !seekable {
 						return it
 					}
+					off, err := r.(io.ReadSeeker).Seek(where, whence)
+					if err != nil {
+						it, _ = it.k(EndErr(err))
+						return it
+					}
+					pos, rest = off, Empty
+					it, rest = it.k(rest)
+					continue
 				}
 
+				if err := ctx.Err(); err != nil {
+					return Stop(err, it.k)
+				}
+				if !opts.Reuse || len(buf) < size {
+					buf = make([]byte, size)
+				}
 				n, err := r.Read(buf)
 				if n > 0 {
 					// process any bytes returned, regardless of errors
 					// cf. https://golang.org/pkg/io/#Reader
-					it, _ = it.k(Chunk(buf[0:n]))
+					pos += int64(n)
+					it, rest = it.k(Chunk(buf[0:n]))
 				}
 				if err != nil {
 					if err != io.EOF {
-						it, _ = it.Feed(End)	// XXX it.Feed(End(err))
+						it, _ = it.Feed(EndErr(err))
+						return it
 					}
 					// NB: end-of-file does not feed End, iteratee can go on
 					//     with another enumerator!
+					if !it.IsCont() {
+						continue
+					}
+					if it, rest = it.k(Empty); it.IsCont() {
+						return it
+					}
+				}
+			}
+			return it
+		})
+	}
+}
+
+// random access to the first 'size' bytes of r. answers Seek, SeekRel,
+// Position, ReadAtLeast, and Flush. every run of the enumerator keeps its own
+// position and reads into fresh buffers, so several iteratees can walk the
+// same r at once, e.g. from different goroutines (cf. io.ReaderAt).
+func ReadAt(r io.ReaderAt, size int64) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			bufsize := DefaultBufSize
+			end := size	// less, should r turn out shorter
+			pos, rest := int64(0), Empty	// cf. Answer
+
+			for it.k != nil {
+				if it.err != nil {
+					// Iteratee has stopped - end here or answer if supported
+					seek := false
+					where := int64(0)
+
+					switch req := it.err.(type) {
+					case Seek:
+						seek = true
+						where = req.Offset
+						if where < 0 {
+							where += end
+						}
+					case SeekRel:
+						seek = true
+						where = pos - int64(rest.Len()) + req.Offset
+					case ReadAtLeast:
+						if req.N > bufsize {
+							bufsize = req.N
+						}
+					}
+
+					if !seek {
+						var ok bool
+						if it, rest, ok = Answer(it, pos, rest); !ok {
+							return it
+						}
+						continue
+					}
+					if where < 0 {
+						it, _ = it.k(EndErr(ErrSeekBeforeStart))
+						return it
+					}
+					pos, rest = where, Empty
+					it, rest = it.k(rest)
+					continue
+				}
+
+				if pos >= end {
+					// NB: no End, cf. read()
+					if it, rest = it.k(Empty); it.IsCont() {
+						return it
+					}
+					continue
+				}
+				n := int64(bufsize)
+				if end - pos < n {
+					n = end - pos
+				}
+				buf := make([]byte, n)
+				m, err := r.ReadAt(buf, pos)
+				if m > 0 {
+					pos += int64(m)
+					it, rest = it.k(Chunk(buf[0:m]))
+				}
+				if err != nil && err != io.EOF {
+					it, _ = it.Feed(EndErr(err))
 					return it
 				}
+				if err != nil && m == 0 {
+					end = pos
+				}
 			}
 			return it
 		})
 	}
 }
 
-// run two enumerators after another
+// run two enumerators after another. requests that a does not answer are
+// passed on to b.
 func (a Enumerator) Append(b Enumerator) Enumerator {
 	return func(it Iteratee) monad.Monad {
 		f := func(it_ interface{}) monad.Monad {
@@ -107,3 +438,153 @@ func (a Enumerator) Append(b Enumerator) Enumerator {
 		return a(it).Bind_(f)
 	}
 }
+
+// run any number of enumerators after another
+func Concat(es ...Enumerator) Enumerator {
+	if len(es) == 0 {
+		return EnumSlice(nil)
+	}
+	e := es[0]
+	for _, b := range es[1:] {
+		e = e.Append(b)
+	}
+	return e
+}
+
+// run e n times
+func Replicate(n int, e Enumerator) Enumerator {
+	es := make([]Enumerator, n)
+	for i := range es {
+		es[i] = e
+	}
+	return Concat(es...)
+}
+
+// run e over and over, for as long as the iteratee is continuing.
+// NB: if e feeds no input, Cycle does not return.
+func Cycle(e Enumerator) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			for it.IsCont() {
+				it = e(it).(monad.IO)().(Iteratee)
+			}
+			return it
+		})
+	}
+}
+
+// feed a chunk from each of es in turn, for as long as the iteratee is
+// continuing and any of es has input left. every enumerator runs in a
+// goroutine of its own, waiting for its turn. an End from one of them just
+// ends its turns, unless it carries an error (cf. EndErr); that is fed to the
+// iteratee, as is the error from a panic. requests are not answered, the
+// iteratee is returned stopped on them. NB: returns once all of es have.
+func Interleave(es ...Enumerator) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			var turns []chan Stream
+			var next []chan bool
+			for _, e := range es {
+				t, n := make(chan Stream), make(chan bool)
+				go interleaved(e, t, n)
+				turns, next = append(turns, t), append(next, n)
+			}
+
+			for i := 0; len(turns) > 0 && it.IsCont(); {
+				i %= len(turns)
+				s, ok := <-turns[i]
+				switch {
+				case !ok:
+					turns = append(turns[:i], turns[i+1:]...)
+					next = append(next[:i], next[i+1:]...)
+				case !s.IsEnd():
+					it, _ = it.Feed(s)
+					next[i] <- it.IsCont()
+					i++
+				case s.Err() != nil:
+					it, _ = it.Feed(s)
+				}
+			}
+
+			// end the others
+			for i := range turns {
+				for s := range turns[i] {
+					if !s.IsEnd() {
+						next[i] <- false
+					}
+				}
+			}
+			if it.IsCont() {
+				it, _ = it.Feed(Empty)	// cf. Control
+			}
+			return it
+		})
+	}
+}
+
+// run e for Interleave: pass every chunk it feeds on 'turns' and wait to
+// hear on 'next' whether to go on.
+func interleaved(e Enumerator, turns chan<- Stream, next <-chan bool) {
+	defer close(turns)
+	var this Iteratee
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if s == Empty {
+			return this, s
+		}
+		turns <- s
+		if s.IsEnd() || !<-next {
+			return Done(nil), s
+		}
+		return this, Empty
+	})
+	_, err := monad.Try(func() interface{} {
+		return e(this).(monad.IO)()
+	})().Get()
+	if err != nil {
+		turns <- EndErr(err)
+	}
+}
+
+// feeds each element of chunks as a chunk of its own. useful to place chunk
+// boundaries at will, e.g. in tests. answers Position, ReadAtLeast, and Flush.
+func EnumSlice(chunks [][]byte) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			pos, rest := int64(0), Empty	// cf. Answer
+
+			for i := 0; it.k != nil; {
+				if it.err != nil {
+					var ok bool
+					if it, rest, ok = Answer(it, pos, rest); !ok {
+						return it
+					}
+					continue
+				}
+
+				if i >= len(chunks) {
+					if it, rest = it.k(Empty); it.IsCont() {
+						break
+					}
+					continue
+				}
+				pos += int64(len(chunks[i]))
+				it, rest = it.k(Chunk(chunks[i]))
+				i++
+			}
+			return it
+		})
+	}
+}
+
+// feeds data in chunks of n bytes (the last one may be shorter)
+func EnumChunksOf(n int, data []byte) Enumerator {
+	if n <= 0 {
+		panic("EnumChunksOf() called with n<=0")
+	}
+	var chunks [][]byte
+	for len(data) > n {
+		chunks = append(chunks, data[:n])
+		data = data[n:]
+	}
+	return EnumSlice(append(chunks, data))
+}
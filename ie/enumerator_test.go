@@ -1,10 +1,15 @@
 package ie
 
 import (
+	"errors"
 	"testing"
+	"io"
 	"os"
 	"strings"
 	"bytes"
+	"fmt"
+	"sync"
+	"testing/iotest"
 
 	"github.com/pesco/go/monad"
 )
@@ -32,21 +37,462 @@ func ExampleAppend() {
 func TestSeek(t *testing.T) {
 	u32 := Uint(BE, 4)
 
+	// a file and memory should behave the same
+	file := bytes.NewReader([]byte("0123456789"))
+	enums := []Enumerator{
+		SeekableRead(file),
+		EnumBytes([]byte("0123456789")),
+		EnumString("0123456789"),
+		ReadAt(file, file.Size()),
+	}
 	testcase := func(it Iteratee, result uint64) {
-		enum := SeekableRead(bytes.NewReader([]byte("0123456789")))
-		it = enum(it).(monad.IO)().(Iteratee)
+		for _, enum := range enums {
+			file.Seek(0, io.SeekStart)
+			it := enum(it).(monad.IO)().(Iteratee)
+
+			if !it.IsDone() {
+				t.Error("should have succeeded; err:", it.Err())
+				continue
+			}
+			r := it.Result().(uint64)
+			if r != result {
+				t.Errorf("wrong result; expected %#v, got %#v", result, r)
+			}
+		}
+	}
+	failcase := func(it Iteratee) {
+		for _, enum := range enums {
+			file.Seek(0, io.SeekStart)
+			it := enum(it).(monad.IO)().(Iteratee)
+
+			if it.IsCont() || it.Request() != nil || it.Err() == nil {
+				t.Error("should have failed; err:", it.Err())
+			}
+		}
+	}
+
+	testcase(u32.Then(Stop(Seek{3}, u32.k)), 0x33343536)
+	testcase(Raise(Seek{2}).Then(u32), 0x32333435)
+	testcase(u32.Then(Raise(Seek{2})).Then(u32), 0x32333435)
+	testcase(Raise(Seek{-4}).Then(u32), 0x36373839)
+	testcase(u32.Then(Raise(SeekRel{-3})).Then(u32), 0x31323334)
+	testcase(Raise(Seek{6}).Then(u32).Then(Raise(Seek{0})).Then(u32), 0x30313233)
+	failcase(Raise(Seek{-20}).Then(u32))
+	failcase(u32.Then(Raise(SeekRel{-5})).Then(u32))
+}
+
+func TestSeekRel(t *testing.T) {
+	u16 := Uint(BE, 2)
+	enum := SeekableRead(bytes.NewReader([]byte("0123456789")))
+	it := enum(u16.Then(Raise(SeekRel{3})).Then(u16)).(monad.IO)().(Iteratee)
+
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	} else if it.Result().(uint64) != 0x3536 {
+		t.Errorf("wrong result; got %#v", it.Result())
+	}
+}
+
+func TestPosition(t *testing.T) {
+	p := new(int64)
+	pos := Raise(Position{p}).Bind(func(interface{}) Iteratee {
+		return Done(*p)
+	})
+
+	enum := SeekableRead(bytes.NewReader([]byte("0123456789")))
+	it := enum(Skip(3).Then(pos)).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	} else if it.Result().(int64) != 3 {
+		t.Errorf("wrong result; got %#v", it.Result())
+	}
 
+	// unsupported requests are passed on
+	enum = Read(bytes.NewReader([]byte("0123456789")))
+	it = enum(Skip(3).Then(Raise(Seek{0}))).(monad.IO)().(Iteratee)
+	if _, ok := it.Request().(Seek); !ok {
+		t.Error("should have stopped on Seek; got:", it.Err())
+	}
+}
+
+func TestTell(t *testing.T) {
+	testcase := func(enum Enumerator, it Iteratee, result int64) {
+		it = enum(it).(monad.IO)().(Iteratee)
 		if !it.IsDone() {
 			t.Error("should have succeeded; err:", it.Err())
 			return
 		}
-		r := it.Result().(uint64)
+		r := it.Result().(int64)
 		if r != result {
-			t.Errorf("wrong result; expected %#v, got %#v", result, r)
+			t.Errorf("wrong result; expected %d, got %d", result, r)
 		}
 	}
 
-	testcase(u32.Then(Stop(Seek{3}, u32.k)), 0x33343536)
-	testcase(Raise(Seek{2}).Then(u32), 0x32333435)
-	testcase(u32.Then(Raise(Seek{2})).Then(u32), 0x32333435)
+	file := func() Enumerator {
+		return SeekableRead(bytes.NewReader([]byte("0123456789")))
+	}
+	slow := func() Enumerator {
+		return Read(iotest.OneByteReader(strings.NewReader("0123456789")))
+	}
+
+	testcase(file(), Tell, 0)
+	testcase(file(), Skip(4).Then(Tell), 4)
+	testcase(file(), Skip(10).Then(Tell), 10)
+	testcase(file(), Raise(Seek{7}).Then(Tell), 7)
+	testcase(file(), Raise(Seek{-2}).Then(Tell), 8)
+	testcase(file(), Skip(2).Then(Raise(SeekRel{3})).Then(Tell), 5)
+	testcase(slow(), Skip(6).Then(Tell), 6)
+	testcase(slow(), Tell.Then(Skip(6)).Then(Tell), 6)
+	testcase(EnumString("0123456789"), Skip(6).Then(Tell), 6)
+	testcase(EnumString("0123456789"), Tell, 0)
+	testcase(EnumBytes([]byte("0123456789")), Raise(Seek{-3}).Then(Tell), 7)
+	testcase(EnumBytes([]byte("0123456789")),
+	         Skip(5).Then(Raise(SeekRel{-2})).Then(Skip(1)).Then(Tell), 4)
+}
+
+type greeting struct{}
+func (greeting) Error() string {return "asked for a greeting"}
+func (greeting) Control() {}
+
+// answers greeting requests by feeding "hallo ", passes on the rest
+func greeter(it Iteratee) monad.Monad {
+	return monad.IO(func() interface{} {
+		for {
+			if _, ok := it.Request().(greeting); !ok {
+				return it
+			}
+			it, _ = it.K(Chunk("hallo "))
+		}
+	})
+}
+
+func TestControl(t *testing.T) {
+	file := SeekableRead(bytes.NewReader([]byte("0123456789")))
+	enum := Enumerator(greeter).Append(file)
+
+	it := Raise(greeting{}).Then(String("hallo ")).
+	      Then(Raise(Seek{5})).Then(Uint(BE, 2))
+	it = enum(it).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	} else if it.Result().(uint64) != 0x3536 {
+		t.Errorf("wrong result; got %#v", it.Result())
+	}
+}
+
+// collect the slices of all chunks, as fed
+func chunks(acc [][]byte) Iteratee {
+	return Cont(func(s Stream) (Iteratee, Stream) {
+		if s == End {
+			return Done(acc), s
+		}
+		if s == Empty {
+			return chunks(acc), s
+		}
+		return chunks(append(acc, s.Slice().([]byte))), Empty
+	})
+}
+
+func TestReadWith(t *testing.T) {
+	testcase := func(it Iteratee, opts Options, expect string) {
+		enum := ReadWith(strings.NewReader("0123456789"), opts)
+		it = enum(it).(monad.IO)().(Iteratee)
+		result := fmt.Sprintf("%s", it.Run())
+		if result != expect {
+			t.Errorf("wrong result; expected %s, got %s", expect, result)
+		}
+	}
+
+	testcase(chunks(nil), Options{4, false}, "[0123 4567 89]")
+	testcase(chunks(nil), Options{0, false}, "[0123456789]")
+	testcase(Raise(ReadAtLeast{6}).Then(chunks(nil)), Options{4, false},
+	         "[012345 6789]")
+	testcase(Skip(1).Then(Raise(ReadAtLeast{6})).Then(chunks(nil)),
+	         Options{4, false}, "[123 456789]")
+
+	// ReadAtLeast holds for one run only
+	r := strings.NewReader("0123456789")
+	enum := ReadWith(r, Options{4, false})
+	enum(Raise(ReadAtLeast{6}).Then(chunks(nil))).(monad.IO)()
+	r.Seek(0, io.SeekStart)
+	it := enum(chunks(nil)).(monad.IO)().(Iteratee)
+	if result := fmt.Sprintf("%s", it.Run()); result != "[0123 4567 89]" {
+		t.Error("wrong result on second run; got:", result)
+	}
+}
+
+func TestReadAt(t *testing.T) {
+	file := bytes.NewReader([]byte("0123456789"))
+	u16 := Uint(BE, 2)
+
+	// walk different regions of the same file concurrently
+	its := []Iteratee{
+		Raise(Seek{2}).Then(u16),
+		Raise(Seek{-2}).Then(u16),
+		u16.Then(Raise(SeekRel{4})).Then(u16),
+		Raise(Seek{5}).Then(Tell),
+	}
+	expect := []interface{}{
+		uint64(0x3233), uint64(0x3839), uint64(0x3637), int64(5),
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, len(its))
+	for i := range its {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			enum := ReadAt(file, file.Size())
+			results[i] = enum(its[i]).(monad.IO)().(Iteratee).Run()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range its {
+		if results[i] != expect[i] {
+			t.Errorf("wrong result %d; expected %#v, got %#v",
+			         i, expect[i], results[i])
+		}
+	}
+
+	// only the first 'size' bytes are read
+	it := ReadAt(file, 4)(chunks(nil)).(monad.IO)().(Iteratee)
+	if r := fmt.Sprintf("%s", it.Run()); r != "[0123]" {
+		t.Error("wrong result; got:", r)
+	}
+
+	// a size too large is found out by every run for itself
+	enum := ReadAt(file, 100)
+	tail := Raise(Seek{-2}).Then(Many([]byte(nil), Any))
+	x, err := enum.Run(tail)().Get()
+	before := fmt.Sprintf("%q %v", x, err)
+	got := make([]interface{}, 8)
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i], _ = enum.Run(Many([]byte(nil), Any))().Get()
+		}(i)
+	}
+	wg.Wait()
+	for i := range got {
+		if string(got[i].([]byte)) != "0123456789" {
+			t.Errorf("wrong result %d; got %q", i, got[i])
+		}
+	}
+	x, err = enum.Run(tail)().Get()
+	if after := fmt.Sprintf("%q %v", x, err); after != before {
+		t.Errorf("Seek{-2} changed from %s to %s", before, after)
+	}
+}
+
+func ExampleConcat() {
+	a := EnumString("hallo ")
+	b := EnumString("welt")
+	c := EnumString("!\n")
+
+	io := Concat(a, b, c)(Write(os.Stdout)).(monad.IO)
+	io()
+	// Output: hallo welt!
+}
+
+func TestReplicate(t *testing.T) {
+	enum := Replicate(3, EnumString("ab"))
+	it := enum(Many([]byte(nil), Any)).(monad.IO)().(Iteratee)
+	if r := string(it.Run().([]byte)); r != "ababab" {
+		t.Error("wrong result; got:", r)
+	}
+
+	enum = Replicate(0, EnumString("ab"))
+	it = enum(Many([]byte(nil), Any)).(monad.IO)().(Iteratee)
+	if r := it.Run().([]byte); len(r) != 0 {
+		t.Error("wrong result; got:", r)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	enum := Cycle(EnumChunksOf(2, []byte("abc")))
+	it := enum(String("abcabcab")).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	}
+
+	// requests end it
+	it = enum(String("abca").Then(Raise(Seek{0}))).(monad.IO)().(Iteratee)
+	if _, ok := it.Request().(Seek); !ok {
+		t.Error("should have stopped on Seek; err:", it.Err())
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	ch := make(chan []byte, 2)
+	ch <- []byte("x")
+	close(ch)
+	enum := Interleave(
+		EnumSlice([][]byte{[]byte("a"), []byte("b"), []byte("c")}),
+		EnumChunksOf(1, []byte("12")),
+		EnumChan(ch),	// feeds End
+	)
+	it := enum(chunks(nil)).(monad.IO)().(Iteratee)
+	if r := fmt.Sprintf("%s", it.Run()); r != "[a 1 x b 2 c]" {
+		t.Error("wrong result; got:", r)
+	}
+
+	// iteratee finishing early; the endless others are ended
+	enum = Interleave(Cycle(EnumString("ab")), EnumString("12"))
+	it = enum(String("ab12ab")).(monad.IO)().(Iteratee)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	}
+
+	// errors
+	boom := errors.New("boom")
+	panicky := func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} { panic(boom) })
+	}
+	for _, e := range []Enumerator{
+		Read(iotest.ErrReader(boom)),
+		panicky,
+	} {
+		enum = Interleave(Cycle(EnumString("ab")), e)
+		_, err := enum.Run(Many([]byte(nil), Any))().Get()
+		if err != boom {
+			t.Error("should have failed with boom; err:", err)
+		}
+	}
+}
+
+func TestEnumChunksOf(t *testing.T) {
+	input := []byte("hello world!")
+	it := Seq(String("hello"), Byte(' '), Tell, String("world"))
+
+	for n := 1; n <= len(input)+1; n++ {
+		i := EnumChunksOf(n, input)(it).(monad.IO)().(Iteratee)
+		if !i.IsDone() {
+			t.Errorf("should have succeeded with %d-byte chunks; err: %v",
+			         n, i.Err())
+		} else if fmt.Sprint(i.Result()) != "[hello 32 6 world]" {
+			t.Errorf("wrong result with %d-byte chunks; got %v", n, i.Result())
+		}
+	}
+
+	i := EnumSlice([][]byte{[]byte("ab"), nil, []byte("c")})(chunks(nil))
+	if r := fmt.Sprintf("%s", i.(monad.IO)().(Iteratee).Run()); r != "[ab c]" {
+		t.Error("wrong result; got:", r)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	// one enumerator per input, run concurrently
+	count := Many([]byte(nil), Any).Bind(func(bs interface{}) Iteratee {
+		return Done(len(bs.([]byte)))
+	})
+	var runs []monad.IO
+	for _, s := range []string{"a", "bb", "ccc"} {
+		runs = append(runs, EnumString(s)(count).(monad.IO))
+	}
+
+	its, err := monad.Parallel(0, runs...).Await().Get()
+	if err != nil {
+		t.Error("should have succeeded; err:", err)
+		return
+	}
+	total := 0
+	for _, it := range its.([]interface{}) {
+		total += it.(Iteratee).Run().(int)
+	}
+	if total != 6 {
+		t.Error("wrong result; got:", total)
+	}
+}
+
+func TestEnumeratorRun(t *testing.T) {
+	x, err := EnumString("hallo").Run(String("hallo"))().Get()
+	if err != nil || x.(string) != "hallo" {
+		t.Error("wrong result; got:", x, err)
+	}
+
+	if _, err := EnumString("hallo").Run(String("welt"))().Get(); err == nil {
+		t.Error("should have failed")
+	}
+
+	// read errors end the input
+	boom := iotest.ErrTimeout
+	r := io.MultiReader(strings.NewReader("hal"), iotest.ErrReader(boom))
+	if _, err := Read(r).Run(String("hallo"))().Get(); err != boom {
+		t.Error("wrong error; got:", err)
+	}
+
+	// data and an error from the same Read: the data counts
+	x, err = Read(dataErrReader{"hallo", boom}).Run(String("hallo"))().Get()
+	if err != nil || x.(string) != "hallo" {
+		t.Error("wrong result; got:", x, err)
+	}
+	x, err = Read(dataErrReader{"hal", boom}).Run(String("hallo"))().Get()
+	if err != boom {
+		t.Error("wrong error; got:", x, err)
+	}
+
+	// a request left over from the last chunk is not overwritten
+	it := Read(dataErrReader{"hallo", boom})(String("hallo").Then(Raise(Seek{0})))
+	if req := it.(monad.IO)().(Iteratee).Request(); req != (Seek{0}) {
+		t.Error("request lost; got:", req)
+	}
+}
+
+// returns all its data and err in one call
+type dataErrReader struct {
+	data string
+	err  error
+}
+func (r dataErrReader) Read(p []byte) (int, error) {
+	return copy(p, r.data), r.err
+}
+
+// a user-defined request: which line are we on? (lines end in ';')
+type lineNo struct{n *int}
+func (lineNo) Error() string {return "asked for the line number"}
+func (lineNo) Control() {}
+
+var getLineNo Iteratee = Cont(func(s Stream) (Iteratee, Stream) {
+	n := new(int)
+	return Stop(lineNo{n}, func(s Stream) (Iteratee, Stream) {
+		return Done(*n), s
+	}), s
+})
+
+// feeds one line per chunk, answering lineNo and the common requests
+func enumLines(lines []string) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			pos, rest := int64(0), Empty
+			for i := 0; it.IsCont() || it.Request() != nil; {
+				switch req := it.Request().(type) {
+				case nil:
+					if i >= len(lines) {
+						return it
+					}
+					pos += int64(len(lines[i]))
+					it, rest = it.K(Chunk(lines[i]))
+					i++
+				case lineNo:
+					*req.n = i
+					it, rest = it.K(rest)
+				default:
+					var ok bool
+					if it, rest, ok = Answer(it, pos, rest); !ok {
+						return it
+					}
+				}
+			}
+			return it
+		})
+	}
+}
+
+func ExampleAnswer() {
+	it := Seq(String("ab;"), getLineNo, String("c"), Tell)
+	it = enumLines([]string{"ab;", "cd;"})(it).(monad.IO)().(Iteratee)
+	fmt.Println(it.Result())
+	// Output: [ab; 1 c 4]
 }
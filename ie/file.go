@@ -0,0 +1,58 @@
+package ie
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// files: the file is opened when the enumerator runs and closed before it
+// returns, also if the iteratee panics. if the file cannot be opened, the
+// iteratee is fed EndErr with the error; read errors are handled as by Read.
+// an iteratee that is done, or stopped on a request, is returned unchanged.
+//
+// like Read, these do not feed End at the end of the file; use
+// Enumerator.Run for a complete action.
+
+// answers the same requests as SeekableRead if the file supports seeking,
+// as regular files do, otherwise the same as Read. for a file that cannot
+// seek, e.g. a pipe, Seek and SeekRel are passed on.
+func EnumFile(path string) Enumerator {
+	return enumFile(path, false)
+}
+
+// like EnumFile, but the file must support seeking; feeds EndErr with
+// ErrNotSeekable otherwise.
+func EnumFileSeekable(path string) Enumerator {
+	return enumFile(path, true)
+}
+
+var ErrNotSeekable = errors.New("file does not support seeking")
+
+func enumFile(path string, mustSeek bool) Enumerator {
+	return func(it Iteratee) monad.Monad {
+		return monad.IO(func() interface{} {
+			if it.k == nil {
+				return it	// done already, don't bother
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				it, _ = it.Feed(EndErr(err))
+				return it
+			}
+			defer f.Close()
+
+			if _, err := f.Seek(0, io.SeekCurrent); err == nil {
+				return SeekableRead(f)(it).(monad.IO)()
+			}
+			if mustSeek {
+				it, _ = it.Feed(EndErr(ErrNotSeekable))
+				return it
+			}
+			return Read(f)(it).(monad.IO)()
+		})
+	}
+}
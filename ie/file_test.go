@@ -0,0 +1,80 @@
+package ie
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pesco/go/monad"
+)
+
+
+func tempFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEnumFile(t *testing.T) {
+	path := tempFile(t, "hallo welt")
+
+	x, err := EnumFile(path).Run(String("hallo welt"))().Get()
+	if err != nil || x.(string) != "hallo welt" {
+		t.Error("wrong result; got:", x, err)
+	}
+
+	// the enumerator can run again
+	it := Seq(String("hallo"), Tell)
+	x, err = EnumFile(path).Run(it)().Get()
+	if err != nil || x.([]interface{})[1].(int64) != 5 {
+		t.Error("wrong result on second run; got:", x, err)
+	}
+
+	// regular files answer Seek
+	seek := Raise(Seek{6}).Then(String("welt"))
+	x, err = EnumFile(path).Run(seek)().Get()
+	if err != nil || x.(string) != "welt" {
+		t.Error("wrong result after Seek; got:", x, err)
+	}
+}
+
+func TestEnumFileSeekable(t *testing.T) {
+	path := tempFile(t, "hallo welt")
+
+	it := Raise(Seek{-4}).Then(String("welt")).
+	      ThenIgnore(Raise(Seek{0})).ThenIgnore(String("hallo"))
+	x, err := EnumFileSeekable(path).Run(it)().Get()
+	if err != nil || x.(string) != "welt" {
+		t.Error("wrong result; got:", x, err)
+	}
+}
+
+func TestEnumFileErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	// the iteratee sees the error
+	it := EnumFile(missing)(String("hallo")).(monad.IO)().(Iteratee)
+	if !errors.Is(it.Err(), os.ErrNotExist) {
+		t.Error("wrong error; got:", it.Err())
+	}
+	if _, err := EnumFile(missing).Run(Many([]byte(nil), Any))().Get();
+	   !errors.Is(err, os.ErrNotExist) {
+		t.Error("wrong error from Run; got:", err)
+	}
+
+	// a pending request is left alone
+	it = EnumFile(missing)(Raise(Seek{0})).(monad.IO)().(Iteratee)
+	if it.Request() != (Seek{0}) {
+		t.Error("request lost; got:", it.Err())
+	}
+
+	// a panicking iteratee surfaces as an error from Run
+	boom := Cont(func(Stream) (Iteratee, Stream) {panic("boom")})
+	path := tempFile(t, "hallo")
+	if _, err := EnumFile(path).Run(boom)().Get(); err == nil {
+		t.Error("panic should have been reported")
+	}
+}
@@ -0,0 +1,158 @@
+// Test helpers for Iteratees
+package ietest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pesco/go/ie"
+)
+
+
+// the observable behaviour of an iteratee on some input
+type outcome struct {
+	done   bool
+	result interface{}
+	rest   []byte		// unconsumed input, if done
+	err    error
+	panic  interface{}
+}
+
+func (o outcome) String() string {
+	switch {
+	case o.panic != nil:
+		return fmt.Sprintf("panic: %v", o.panic)
+	case o.done:
+		return fmt.Sprintf("done: %#v, rest %q", o.result, o.rest)
+	default:
+		return fmt.Sprintf("stopped: %v", o.err)
+	}
+}
+
+func (o outcome) equals(p outcome) bool {
+	if o.done != p.done || (o.panic == nil) != (p.panic == nil) {
+		return false
+	}
+	if o.done {
+		return reflect.DeepEqual(o.result, p.result) &&
+		       bytes.Equal(o.rest, p.rest)
+	}
+	return true	// error messages may legitimately differ
+}
+
+// feed the chunks to 'it', followed by End if it is still continuing.
+func run(it ie.Iteratee, chunks [][]byte) (o outcome) {
+	defer func() {
+		o.panic = recover()
+	}()
+
+	var s ie.Stream
+	for i, chunk := range chunks {
+		it, s = it.Feed(ie.Chunk(chunk))
+		if !it.IsCont() {
+			o.rest = append(bytesOf(s), bytes.Join(chunks[i+1:], nil)...)
+			break
+		}
+	}
+	if it.IsCont() {
+		it, _ = it.Feed(ie.End)
+	}
+
+	o.done = it.IsDone()
+	o.result = it.Result()
+	o.err = it.Err()
+	return
+}
+
+func bytesOf(s ie.Stream) []byte {
+	if s == ie.End || s == ie.Empty {
+		return nil
+	}
+	return s.Slice().([]byte)
+}
+
+// split input into chunks of the given sizes, the rest going into the last
+func Split(input []byte, sizes ...int) [][]byte {
+	var chunks [][]byte
+	for _, n := range sizes {
+		if n > len(input) {
+			n = len(input)
+		}
+		chunks = append(chunks, input[:n])
+		input = input[n:]
+	}
+	return append(chunks, input)
+}
+
+// check that feeding 'it' the given chunks gives the same result and leftover
+// as feeding their concatenation in one piece.
+func CheckSplit(t testing.TB, it ie.Iteratee, chunks [][]byte) bool {
+	t.Helper()
+	input := bytes.Join(chunks, nil)
+	expect := run(it, [][]byte{input})
+	got := run(it, chunks)
+	if !got.equals(expect) {
+		t.Errorf("chunk boundaries matter on input %q;\n"+
+		         "in one piece: %v\nsplit as %q: %v",
+		         input, expect, chunks, got)
+		return false
+	}
+	return true
+}
+
+// check that 'it' gives the same result and leftover on input regardless of
+// how it is split into chunks. tries every split into two chunks, one-byte
+// chunks, and a number of random splits.
+// NB: bit streams are not supported.
+func CheckChunkInvariance(t testing.TB, it ie.Iteratee, input []byte) {
+	t.Helper()
+	for i := 0; i <= len(input); i++ {
+		if !CheckSplit(t, it, Split(input, i)) {
+			return
+		}
+	}
+
+	ones := make([]int, len(input))
+	for i := range ones {
+		ones[i] = 1
+	}
+	if !CheckSplit(t, it, Split(input, ones...)) {
+		return
+	}
+
+	rnd := rand.New(rand.NewSource(int64(len(input))))
+	for i := 0; i < 32 && len(input) > 2; i++ {
+		var sizes []int
+		for n := len(input); n > 0; {
+			k := rnd.Intn(n + 1)
+			sizes = append(sizes, k)
+			n -= k
+		}
+		if !CheckSplit(t, it, Split(input, sizes...)) {
+			return
+		}
+	}
+}
+
+// run Go's native fuzzing on 'it', looking for inputs and chunk boundaries on
+// which it behaves differently when the input comes in one piece.
+// to be called from a FuzzXxx function, e.g.:
+//
+//   func FuzzRequest(f *testing.F) {
+//       ietest.Fuzz(f, request, []byte("GET / HTTP/1.1\r\n"))
+//   }
+func Fuzz(f *testing.F, it ie.Iteratee, seeds ...[]byte) {
+	for _, seed := range seeds {
+		f.Add(seed, []byte{byte(len(seed) / 2)})
+	}
+	f.Fuzz(func(t *testing.T, input []byte, sizes []byte) {
+		ns := make([]int, len(sizes))
+		for i, n := range sizes {
+			ns[i] = int(n)
+		}
+		CheckSplit(t, it, Split(input, ns...))
+	})
+}
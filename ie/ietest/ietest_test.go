@@ -0,0 +1,57 @@
+package ietest
+
+import (
+	"testing"
+
+	"github.com/pesco/go/ie"
+)
+
+
+var greeting = ie.Seq(ie.String("hello"), ie.Byte(' '),
+                      ie.Many([]byte(nil), ie.NoneOf([]byte("!"))),
+                      ie.Byte('!'))
+
+// records failures instead of reporting them
+type recorder struct {
+	testing.TB
+	failed bool
+}
+func (r *recorder) Errorf(string, ...interface{}) {r.failed = true}
+func (r *recorder) Helper() {}
+
+func TestCheckChunkInvariance(t *testing.T) {
+	CheckChunkInvariance(t, greeting, []byte("hello world!"))
+	CheckChunkInvariance(t, greeting, []byte("hello world!?"))
+	CheckChunkInvariance(t, greeting, []byte("hallo welt!"))
+	CheckChunkInvariance(t, greeting, []byte("hello"))
+	CheckChunkInvariance(t, ie.Uint(ie.BE, 4), []byte("\x01\x02\x03\x04\x05"))
+
+	// an iteratee that takes whatever comes first
+	first := ie.Cont(func(s ie.Stream) (ie.Iteratee, ie.Stream) {
+		return ie.Done(string(s.Slice().([]byte))), ie.Empty
+	})
+	r := &recorder{TB: t}
+	CheckChunkInvariance(r, first, []byte("hello"))
+	if !r.failed {
+		t.Error("should have noticed chunk boundaries")
+	}
+
+	// Optional needs lookahead across chunk boundaries
+	r = &recorder{TB: t}
+	CheckChunkInvariance(r, ie.Optional(ie.String("XYZ")), []byte("XYa"))
+	if !r.failed {
+		t.Error("should have noticed the lookahead panic")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	chunks := Split([]byte("hello"), 2, 0, 9)
+	if len(chunks) != 4 || string(chunks[0]) != "he" ||
+	   len(chunks[1]) != 0 || string(chunks[2]) != "llo" || len(chunks[3]) != 0 {
+		t.Errorf("wrong result; got %q", chunks)
+	}
+}
+
+func FuzzGreeting(f *testing.F) {
+	Fuzz(f, greeting, []byte("hello world!"), []byte("hello!"))
+}
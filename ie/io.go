@@ -0,0 +1,226 @@
+package ie
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// adapters between iteratees and the io package...
+
+// returned from writes to an IterWriter whose iteratee is done
+var ErrDone = errors.New("iteratee is done")
+
+var errRunning = errors.New("iteratee has not finished (not closed?)")
+
+// an io.Writer that feeds everything written to an iteratee. this allows an
+// iteratee to consume data pushed by a producer, e.g. io.Copy or exec.Cmd.
+type IterWriter struct {
+	it Iteratee
+}
+
+func NewWriter(it Iteratee) *IterWriter {
+	return &IterWriter{it}
+}
+
+// feeds p as a chunk. returns an error as soon as the iteratee has stopped,
+// be it on failure or request (requests are not answered), or ErrDone if it
+// is done. in either case, n counts the bytes that were consumed.
+func (w *IterWriter) Write(p []byte) (n int, err error) {
+	if w.it.k == nil {
+		return 0, ErrDone
+	}
+	if w.it.err != nil {
+		return 0, w.it.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// p must not be retained, cf. io.Writer
+	var rest Stream
+	w.it, rest = w.it.k(Chunk(append([]byte(nil), p...)))
+	n = len(p) - rest.Len()
+
+	if w.it.k == nil && n < len(p) {
+		return n, ErrDone
+	}
+	if w.it.err != nil {
+		return n, w.it.err
+	}
+	return n, nil
+}
+
+// feeds End, unless the iteratee is already done or stopped. returns the
+// error the iteratee stopped with, if any.
+func (w *IterWriter) Close() error {
+	if w.it.k != nil && w.it.err == nil {
+		w.it, _ = w.it.k(End)
+	}
+	_, err := w.Result()
+	return err
+}
+
+// the iteratee's result or the error it stopped with
+func (w *IterWriter) Result() (interface{}, error) {
+	if w.it.k == nil {
+		return w.it.result, nil
+	}
+	if w.it.err != nil {
+		return nil, w.it.err
+	}
+	return nil, errRunning
+}
+
+// an io.ReadCloser for the output of an enumerator, e.g. a pipeline built
+// with Pipe. the enumerator is run in a goroutine, started by the first Read;
+// at the end of its input, it feeds End. a failure or unanswered request
+// stopping the pipeline is returned from Read.
+// the enumerator must yield a monad.IO, like all enumerators in this package.
+func ToReader(e Enumerator) io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &enumReader{e: e, pr: pr, pw: pw}
+}
+
+type enumReader struct {
+	e     Enumerator
+	once  sync.Once
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+}
+
+func (r *enumReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {go r.run()})
+	return r.pr.Read(p)
+}
+
+// stops the pipeline; its next write fails
+func (r *enumReader) Close() error {
+	r.once.Do(func() {})	// never start
+	return r.pr.Close()
+}
+
+func (r *enumReader) run() {
+	it := r.e(Write(r.pw)).(monad.IO)().(Iteratee)
+	if it.IsCont() {
+		it, _ = it.k(End)
+	}
+	if it.err != nil {
+		r.pw.CloseWithError(it.err)
+	} else {
+		r.pw.Close()
+	}
+}
+
+// a bufio.SplitFunc that recognizes one token with 'it' per call. 'it' is run
+// from the start on all data buffered by the scanner; while it continues, the
+// scanner is asked for more. when it is done, the input it consumed is
+// advanced over and its result returned as the token. the result must be
+// []byte, string, byte, or nil (no token). if it fails, so does the scanner.
+// NB: like any SplitFunc, 'it' must consume input when it succeeds.
+func SplitFunc(it Iteratee) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		it, rest := it.Feed(Chunk(data))
+		if atEOF && it.IsCont() {
+			it, rest = it.k(End)
+		}
+		if it.IsCont() {
+			return 0, nil, nil
+		}
+		if it.err != nil {
+			return 0, nil, it.err
+		}
+
+		advance := len(data) - rest.Len()
+		switch x := it.result.(type) {
+		case []byte:
+			if x == nil {
+				x = []byte{}	// empty token
+			}
+			return advance, x, nil
+		case string:
+			return advance, []byte(x), nil
+		case byte:
+			return advance, []byte{x}, nil
+		case nil:
+			return advance, nil, nil
+		default:
+			return 0, nil, fmt.Errorf("SplitFunc: %T result is not a token", x)
+		}
+	}
+}
+
+// an enumeratee that splits its input with a bufio.SplitFunc, e.g.
+// bufio.ScanRunes. it feeds the tokens to the inner iteratee, each as a chunk
+// of one []byte element. like bufio.Scanner, it gives up on a SplitFunc that
+// returns too many empty tokens in a row without advancing; it fails with
+// ErrNoProgress then.
+func FromSplitFunc(split bufio.SplitFunc) Enumeratee {
+	return func(inner Iteratee) Iteratee {
+		return splitter(split, nil, inner)
+	}
+}
+
+var ErrNoProgress = errors.New("split function returns empty tokens without advancing")
+
+const maxEmptyTokens = 100	// as in bufio.Scanner
+
+func splitter(split bufio.SplitFunc, buf []byte, inner Iteratee) Iteratee {
+	return Cont(func(s Stream) (Iteratee, Stream) {
+		atEOF := s.IsEnd()
+		if !atEOF && s != Empty {
+			// copy, the chunk need not remain valid (cf. Options)
+			buf = append(buf[:len(buf):len(buf)], s.Slice().([]byte)...)
+		}
+
+		for empty := 0; ; {
+			advance, token, err := split(buf, atEOF)
+			final := err == bufio.ErrFinalToken
+			if err != nil && !final {
+				return Fail(err), s
+			}
+			if advance == 0 && token == nil && !final {
+				break	// need more input
+			}
+			buf = buf[advance:]
+
+			if advance > 0 {
+				empty = 0
+			} else if empty++; empty > maxEmptyTokens {
+				return Fail(ErrNoProgress), s
+			}
+			if token != nil {
+				inner, _ = inner.Feed(Chunk([][]byte{token}))
+				if !inner.IsCont() {
+					return Done(inner), leftover(buf, s)
+				}
+			}
+			if final {
+				inner, _ = inner.Feed(End)
+				return Done(inner), leftover(buf, s)
+			}
+		}
+
+		if atEOF {
+			inner, _ = inner.Feed(s)	// with any error
+			return Done(inner), s
+		}
+		return splitter(split, buf, inner), Empty
+	})
+}
+
+// the unconsumed part of buf as a stream, s (End) if there is none
+func leftover(buf []byte, s Stream) Stream {
+	if len(buf) == 0 && s.IsEnd() {
+		return s
+	}
+	return Chunk(buf)
+}
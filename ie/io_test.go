@@ -0,0 +1,139 @@
+package ie
+
+import (
+	"testing"
+	"fmt"
+	"io"
+	"strings"
+	"encoding/json"
+	"bufio"
+	"testing/iotest"
+
+	"github.com/pesco/go/monad"
+)
+
+
+func ExampleNewWriter() {
+	w := NewWriter(Many([]byte(nil), NoneOf([]byte(" "))))
+	io.Copy(w, strings.NewReader("hallo welt"))
+	w.Close()
+	fmt.Println(w.Result())
+	// Output: [104 97 108 108 111] <nil>
+}
+
+func TestIterWriter(t *testing.T) {
+	w := NewWriter(String("hallo").ThenIgnore(EndOfInput))
+	if _, err := w.Write([]byte("hal")); err != nil {
+		t.Error("should have accepted input; err:", err)
+	}
+	if _, err := w.Result(); err == nil {
+		t.Error("should not have a result before Close")
+	}
+	if err := w.Close(); err == nil {
+		t.Error("should have failed on Close")
+	}
+
+	// the producer is stopped early
+	w = NewWriter(String("hallo"))
+	r := strings.NewReader("hallo welt")
+	n, err := io.Copy(w, r)
+	if err != ErrDone || n != 5 {
+		t.Errorf("should have stopped after 5 bytes; got %d, %v", n, err)
+	}
+
+	w = NewWriter(String("hallo"))
+	n, err = io.Copy(w, strings.NewReader("hello world"))
+	if _, ok := err.(NoMatch); !ok {
+		t.Error("should have failed to match; got:", n, err)
+	}
+	if _, err = w.Write([]byte("hallo")); err == nil {
+		t.Error("should have failed after failure")
+	}
+}
+
+func TestToReader(t *testing.T) {
+	input := Read(strings.NewReader("[1, 2, 3]\n[4, 5, 6]\n"))
+	r := ToReader(input.Pipe(BreakAfter([]byte("\n"))))
+
+	var xs []int
+	err := json.NewDecoder(r).Decode(&xs)
+	if err != nil {
+		t.Error("should have succeeded; err:", err)
+	} else if fmt.Sprint(xs) != "[1 2 3]" {
+		t.Error("wrong result; got:", xs)
+	}
+	r.Close()
+
+	// failures are passed on
+	fail := EnumString("hallo").Pipe(Prefix(String("hello"), Pass))
+	_, err = io.ReadAll(ToReader(fail))
+	if _, ok := err.(NoMatch); !ok {
+		t.Error("should have failed to match; got:", err)
+	}
+
+	// closing early stops the pipeline
+	r = ToReader(Read(strings.NewReader(strings.Repeat("x", 4096))))
+	buf := make([]byte, 10)
+	if n, _ := r.Read(buf); n == 0 {
+		t.Error("should have read something")
+	}
+	r.Close()
+	if _, err := r.Read(buf); err != io.ErrClosedPipe {
+		t.Error("should have been closed; got:", err)
+	}
+}
+
+func TestSplitFunc(t *testing.T) {
+	input := "GET / HTTP/1.1\r\nHost: x\r\n\r\ntrailing"
+	line := Many([]byte(nil), NoneOf([]byte("\r\n"))).ThenIgnore(String("\r\n"))
+
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	scanner.Split(SplitFunc(line))
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if fmt.Sprintf("%q", lines) != `["GET / HTTP/1.1" "Host: x" ""]` {
+		t.Errorf("wrong result; got %q", lines)
+	}
+	if _, ok := scanner.Err().(NoMatch); !ok {
+		t.Error("should have failed on the trailing line; got:", scanner.Err())
+	}
+}
+
+func TestFromSplitFunc(t *testing.T) {
+	words := FromSplitFunc(bufio.ScanWords)
+	enum := Read(iotest.HalfReader(strings.NewReader(" hallo  welt\nwie geht's")))
+
+	it := enum.Pipe(words)(Many([][]byte(nil), Any)).(monad.IO)().(Iteratee)
+	result := it.Run()
+	if fmt.Sprintf("%s", result) != "[hallo welt wie geht's]" {
+		t.Errorf("wrong result; got %s", result)
+	}
+
+	// inner iteratee finishing early
+	it = words(Any)
+	it, s := it.Feed(Chunk("hal"))
+	if !it.IsCont() {
+		t.Error("should have suspended")
+	}
+	it, s = it.Feed(Chunk("lo welt !"))
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	} else if !eq(s, "welt !") {
+		t.Errorf("consumed wrong; left: %q", s.Slice())
+	}
+	if r := it.Result().(Iteratee).Run(); string(r.([]byte)) != "hallo" {
+		t.Errorf("wrong result; got %s", r)
+	}
+
+	// split function that never advances
+	stuck := func(data []byte, atEOF bool) (int, []byte, error) {
+		return 0, []byte{}, nil
+	}
+	it = FromSplitFunc(stuck)(Many([][]byte(nil), Any))
+	if it, _ = it.Feed(Chunk("hallo")); it.Err() != ErrNoProgress {
+		t.Error("should have failed with ErrNoProgress; err:", it.Err())
+	}
+}
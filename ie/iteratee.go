@@ -113,6 +113,11 @@ func (a Iteratee) ThenReturn(x interface{}) Iteratee {
 	return a.Then(Done(x))
 }
 
+// Done for the generic helpers in package monad
+func Return_(x interface{}) monad.Monad {
+	return Done(x)
+}
+
 func (a Iteratee) ThenReturn_(x interface{}) monad.Monad {
 	return a.ThenReturn(x)
 }
@@ -144,8 +149,8 @@ func Bracket(before, it, after Iteratee) Iteratee {
 // consume and return the first element of the input
 var Head Iteratee = Cont(k_head)
 func k_head(s Stream) (Iteratee, Stream) {
-	if s == End {
-		return Fail(NoMatch{"end of input"}), s
+	if s.IsEnd() {
+		return Fail(s.errOr(NoMatch{"end of input"})), s
 	}
 	if s == Empty {
 		return Cont(k_head), s
@@ -170,9 +175,15 @@ func Skip(n int) Iteratee {
 
 func Write(w io.Writer) (this Iteratee) {
 	this = Cont(func(s Stream) (Iteratee, Stream) {
-		if s == End {
+		if s.IsEnd() {
+			if err := s.Err(); err != nil {
+				return Fail(err), s
+			}
 			return Done(nil), s
 		}
+		if s == Empty {
+			return this, s
+		}
 		bs := s.Slice().([]byte)
 		n, err := w.Write(bs)
 		if n > 0 {
@@ -5,6 +5,11 @@ import (
 	"os"
 	"fmt"
 	"strings"
+	"reflect"
+	"math/rand"
+
+	"github.com/pesco/go/monad"
+	"github.com/pesco/go/monad/monadtest"
 )
 
 
@@ -587,3 +592,72 @@ func TestSkip(t *testing.T) {
 	testcase_fail(Skip(5), "012")
 	testcase_fail(Skip(5), "0123")
 }
+
+// iteratees are equivalent if they behave the same on some inputs
+func equalIteratees(a_, b_ monad.Monad) bool {
+	type outcome struct {
+		done   bool
+		result interface{}
+		rest   string
+	}
+	run := func(it Iteratee, input string, n int) (o outcome) {
+		var s Stream
+		for it.IsCont() && len(input) > 0 {
+			if n > len(input) {
+				n = len(input)
+			}
+			it, s = it.Feed(Chunk(input[:n]))
+			input = input[n:]
+		}
+		if it.IsCont() {
+			it, s = it.Feed(End)
+		}
+		if s != End && s != Empty {
+			o.rest = string(s.Slice().([]byte))
+		}
+		o.done = it.IsDone()
+		o.result = it.Result()
+		o.rest += input
+		return
+	}
+
+	a, b := a_.(Iteratee), b_.(Iteratee)
+	for _, input := range []string{"", "0", "0123456789"} {
+		for _, n := range []int{1, 3, 10} {
+			if !reflect.DeepEqual(run(a, input, n), run(b, input, n)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func randomIteratee(rnd *rand.Rand, x interface{}) Iteratee {
+	k := rnd.Intn(4)
+	switch rnd.Intn(5) {
+	case 0:  return Done(x)
+	case 1:  return Head.Bind(func(y interface{}) Iteratee {
+	             return Done([]interface{}{x, y})
+	         })
+	case 2:  return Skip(k).ThenReturn(x)
+	case 3:  return Uint(BE, uint(k)).ThenReturn(x)
+	default: return Fail(NoMatch{"random"})
+	}
+}
+
+func TestMonadLaws(t *testing.T) {
+	monadtest.Check(t, monadtest.Laws{
+		Return: func(x interface{}) monad.Monad {return Done(x)},
+		Equal:  equalIteratees,
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			return randomIteratee(rnd, rnd.Intn(10))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			seed := rnd.Int63()
+			return func(x interface{}) monad.Monad {
+				return randomIteratee(rand.New(rand.NewSource(seed)), x)
+			}
+		},
+	})
+}
@@ -1,14 +1,30 @@
 package ie
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/pesco/go/monad"
 )
 
 
 type NoMatch struct {Expect string}
 func (e NoMatch) Error() string {return (e.Expect + ": no match")}
 
+// a parse failure inside one or more named contexts, outermost first.
+// cf. Context
+type InContext struct {
+	Path []string
+	Err  error
+}
+func (e InContext) Error() string {
+	return "in " + strings.Join(e.Path, " > ") + ": " + e.Err.Error()
+}
+func (e InContext) Unwrap() error {return e.Err}
+
 
 // primitive parsers...
 
@@ -17,9 +33,12 @@ func k_eof(s Stream) (Iteratee, Stream) {
 	if s == Empty {
 		return Cont(k_eof), s
 	}
-	if s != End {
+	if !s.IsEnd() {
 		return Fail(NoMatch{"expected end of input"}), s
 	}
+	if err := s.Err(); err != nil {
+		return Fail(err), s
+	}
 	return Done(nil), s
 }
 
@@ -28,8 +47,8 @@ var Any Iteratee = Head
 
 func Byte(b byte) (this Iteratee) {
 	this = Cont(func(s Stream) (Iteratee, Stream) {
-		if s == End {
-			return Fail(NoMatch{fmt.Sprintf("%q (unexpected end of input)", b)}), s
+		if s.IsEnd() {
+			return Fail(s.errOr(NoMatch{fmt.Sprintf("%q (unexpected end of input)", b)})), s
 		}
 		if s == Empty {
 			return this, s
@@ -53,8 +72,8 @@ func string_(x string) (this Iteratee) {
 		return Done(nil)
 	}
 	this = Cont(func(s Stream) (Iteratee, Stream) {
-		if s == End {
-			return Fail(NoMatch{fmt.Sprintf("%q (unexpected end of input)", x)}), s
+		if s.IsEnd() {
+			return Fail(s.errOr(NoMatch{fmt.Sprintf("%q (unexpected end of input)", x)})), s
 		}
 		if s == Empty {
 			return this, s
@@ -76,8 +95,8 @@ func string_(x string) (this Iteratee) {
 
 func oneof(bitset [4]uint64) (this Iteratee) {
 	this = Cont(func(s Stream) (Iteratee, Stream) {
-		if s == End {
-			return Fail(NoMatch{"unexpected end of input"}), s
+		if s.IsEnd() {
+			return Fail(s.errOr(NoMatch{"unexpected end of input"})), s
 		}
 		if s == Empty {
 			return this, s
@@ -149,8 +168,9 @@ func ui(byteorder Endianness, n uint,
 	var iter func(res uint64, pos uint) Iteratee
 	iter = func(res uint64, pos uint) (this Iteratee) {
 		return Cont(func(s Stream) (Iteratee, Stream) {
-			if s == End {
-				return Fail(NoMatch{fmt.Sprintf("Uint(%d): unexpected end of input", n)}), s
+			if s.IsEnd() {
+				msg := fmt.Sprintf("Uint(%d): unexpected end of input", n)
+				return Fail(s.errOr(NoMatch{msg})), s
 			}
 			if s == Empty {
 				return this, s
@@ -247,8 +267,9 @@ func Bits(bitorder Endianness, n uint8) Iteratee {
 	var iter func(res uint64, pos uint8, n uint8) Iteratee
 	iter = func(res uint64, pos uint8, n uint8) (this Iteratee) {
 		this = Cont(func(s Stream) (Iteratee, Stream) {
-			if s == End {
-				return Fail(NoMatch{fmt.Sprintf("Bits(%d): unexpected end of input", n)}), s
+			if s.IsEnd() {
+				msg := fmt.Sprintf("Bits(%d): unexpected end of input", n)
+				return Fail(s.errOr(NoMatch{msg})), s
 			}
 			if s == Empty {
 				return this, s
@@ -399,36 +420,27 @@ func StructSize(T reflect.Type) int {
 
 // returns results as a []interface{}
 func Seq(its ...Iteratee) Iteratee {
-	if len(its) == 0 {
-		return Done([]interface{}(nil))
-	}
-	return seq1(0, its)
-}
-
-func seq1(i int, its []Iteratee) Iteratee {
-	if i >= len(its) {
-		slice := make([]interface{}, len(its))
-		return Done(slice)
-	}
-
-	return its[i].Bind(func(x interface{}) Iteratee {
-		return seq1(i+1, its).Bind(func(sl interface{}) Iteratee {
-			slice := sl.([]interface{})
-			slice[i] = x
-			return Done(slice)
-		})
-	})
+	return monad.Sequence(Return_, monads(its)...).(Iteratee)
 }
 
 // discards results
 func Seq_(its ...Iteratee) Iteratee {
-	if len(its) == 0 {
-		return Done(nil)
+	return monad.Sequence_(Return_, monads(its)...).(Iteratee)
+}
+
+func monads(its []Iteratee) []monad.Monad {
+	ms := make([]monad.Monad, len(its))
+	for i, it := range its {
+		ms[i] = it
 	}
+	return ms
+}
 
-	return its[0].Bind(func(_ interface{}) Iteratee {
-		return Seq_(its[1:]...)
-	})
+// an alternative that failed with the error of an EndErr is not a mismatch
+// but broken input; a choice fails with it rather than trying the others.
+func endFailure(it Iteratee, s Stream) bool {
+	err := s.Err()
+	return err != nil && it.IsStop() && errors.Is(it.err, err)
 }
 
 // run all arguments in parallel, return first result found
@@ -444,6 +456,9 @@ func Choice(its ...Iteratee) Iteratee {
 			if it.k == nil {
 				return Done(it.result), t
 			}
+			if endFailure(it, s) {
+				return it, t
+			}
 			if it.err == nil {
 				rest = append(rest, it)
 			}
@@ -475,11 +490,14 @@ func ochoice(its []Iteratee, commit bool) Iteratee {
 		rest := []Iteratee(nil)
 		for _, it := range its {
 			it, t := it.Feed(s)
+			if endFailure(it, s) {
+				return it, t
+			}
 			if it.k == nil {	// is done
 				if rest == nil {
 					// all previous iteratees failed -> match
 					return Done(it.result), t
-				} else if (t != Empty && t != End) {
+				} else if (t != Empty && !t.IsEnd()) {
 					// this iteratee succeeded and left some input, but others
 					// before it are suspended waiting for another chunk.
 					// since we don't support rewinding the input stream, we
@@ -494,7 +512,7 @@ func ochoice(its []Iteratee, commit bool) Iteratee {
 				rest = append(rest, it)
 			}
 		}
-		if s != End {
+		if !s.IsEnd() {
 			s = Empty
 		}
 		return ochoice(rest, commit), s
@@ -533,7 +551,10 @@ func Many1_(it Iteratee) Iteratee {
 // iteratee. passes the error that 'it' stopped with.
 func ManyEnd(slice interface{}, it Iteratee) Iteratee {
 	return Cont(func(s Stream) (Iteratee, Stream) {
-		if s == End {
+		if s.IsEnd() {
+			if err := s.Err(); err != nil {
+				return Fail(err), s
+			}
 			return Done(slice), s
 		}
 		return Many1End(slice, it).Feed(s)
@@ -581,3 +602,152 @@ func Validate(it Iteratee, pred func(interface{}) bool) Iteratee {
 		}
 	})
 }
+
+// replace the error message of a failing parser with "expected <name>".
+// the position of the failure is unaffected.
+func Label(name string, it Iteratee) Iteratee {
+	return mapfail(it, func(error) error {
+		return NoMatch{"expected " + name}
+	})
+}
+
+// push 'name' onto the breadcrumb path of a failing parser's error, e.g.:
+//
+//   Context("request-line", Seq(Context("method", Label("token", tok)), ...))
+//
+// fails with "in request-line > method: expected token: no match".
+func Context(name string, it Iteratee) Iteratee {
+	return mapfail(it, func(err error) error {
+		if c, ok := err.(InContext); ok {
+			return InContext{append([]string{name}, c.Path...), c.Err}
+		}
+		return InContext{[]string{name}, err}
+	})
+}
+
+// apply f to the error of 'it' whenever it fails to match. other stops, such
+// as Seek requests to the enumerator, pass through unchanged.
+func mapfail(it Iteratee, f func(error) error) Iteratee {
+	if it.k == nil {
+		return it
+	}
+	k := func(s Stream) (Iteratee, Stream) {
+		it, s := it.k(s)
+		return mapfail(it, f), s
+	}
+	err := it.err
+	if isNoMatch(err) {
+		err = f(err)
+	}
+	return Iteratee{nil, k, err}
+}
+
+func isNoMatch(err error) bool {
+	switch err.(type) {
+	case NoMatch, InContext:
+		return true
+	}
+	return false
+}
+
+
+// error recovery...
+
+// result of Recover in place of the result of a failed parser
+type Recovered struct {
+	Err error
+}
+
+// result of ManyRecover
+type Partial struct {
+	Results interface{}	// []T as passed to ManyRecover
+	Errs    []error
+}
+
+// run 'it'; if it fails to match, resynchronize the input with 'sync' and
+// return a Recovered carrying the error. sync starts where 'it' failed and
+// should consume input up to the next good spot, e.g. SkipPast([]byte("\n")).
+func Recover(it, sync Iteratee) Iteratee {
+	if it.k == nil {
+		return it
+	}
+	if isNoMatch(it.err) {
+		return sync.ThenReturn(Recovered{it.err})
+	}
+	k := func(s Stream) (Iteratee, Stream) {
+		it, s := it.k(s)
+		if it.k != nil && isNoMatch(it.err) {
+			return sync.ThenReturn(Recovered{it.err}).Feed(s)
+		}
+		return Recover(it, sync), s
+	}
+	return Iteratee{nil, k, it.err}
+}
+
+// a variant of ManyEnd that skips damaged parts of the input using
+// Recover(it, sync). returns a Partial with the results appended to 'slice'
+// and the errors encountered on the way.
+// NB: sync must consume input whenever 'it' fails, or this will not terminate.
+func ManyRecover(slice interface{}, it, sync Iteratee) Iteratee {
+	return manyrecover(Partial{slice, nil}, Recover(it, sync))
+}
+func manyrecover(p Partial, it Iteratee) (this Iteratee) {
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if s.IsEnd() {
+			if err := s.Err(); err != nil {
+				return Fail(err), s
+			}
+			return Done(p), s
+		}
+		if s == Empty {
+			return this, s
+		}
+		return it.Bind(func(x interface{}) Iteratee {
+			if r, ok := x.(Recovered); ok {
+				p.Errs = append(p.Errs, r.Err)
+			} else {
+				vslice := reflect.ValueOf(p.Results)
+				vslice = reflect.Append(vslice, reflect.ValueOf(x))
+				p.Results = vslice.Interface()
+			}
+			return manyrecover(p, it)
+		}).Feed(s)
+	})
+	return
+}
+
+// consume and discard input up to and including the next occurrence of marker.
+// also succeeds at the end of input, as if the marker was found there.
+func SkipPast(marker []byte) Iteratee {
+	if len(marker) == 0 {
+		return Done(nil)
+	}
+	return skippast(marker, nil)
+}
+func skippast(marker, carry []byte) (this Iteratee) {
+	this = Cont(func(s Stream) (Iteratee, Stream) {
+		if s.IsEnd() {
+			if err := s.Err(); err != nil {
+				return Fail(err), s
+			}
+			return Done(nil), s
+		}
+		if s == Empty {
+			return this, s
+		}
+		bs := s.Slice().([]byte)
+
+		// carry holds the tail of the previous chunk in case the marker
+		// straddles the chunk boundary.
+		buf := append(append([]byte(nil), carry...), bs...)
+		idx := bytes.Index(buf, marker)
+		if idx != -1 {
+			return Done(nil), Chunk(bs[idx+len(marker)-len(carry):])
+		}
+		if len(buf) >= len(marker) {
+			buf = buf[len(buf)-len(marker)+1:]
+		}
+		return skippast(marker, buf), Empty
+	})
+	return
+}
@@ -1,8 +1,11 @@
 package ie
 
 import (
+	"errors"
 	"testing"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/pesco/go/monad"
 )
@@ -317,3 +320,149 @@ func TestStruct(t *testing.T) {
 	testcase((*TS1)(nil), "0123456789", TS1{0x3130,[3]uint8{0x32,0x33,0x34},0x38373635}, "9")
 	testcase((*TS2)(nil), "0123456789", TS2{0x3130,[3]uint8{},0x38373635}, "9")
 }
+
+func TestLabel(t *testing.T) {
+	method := Label("HTTP method", Choice(String("GET"), String("POST")))
+
+	i, s := method.Feed(Chunk("PUT /"))
+	if !i.IsStop() {
+		t.Error("should have failed")
+	} else if i.Err().Error() != "expected HTTP method: no match" {
+		t.Error("wrong error; got:", i.Err())
+	}
+
+	i, s = method.Feed(Chunk("PO"))
+	i, s = i.Feed(Chunk("ST /"))
+	if !i.IsDone() {
+		t.Error("should have succeeded; err:", i.Err())
+	} else if i.Result().(string) != "POST" || !eq(s, " /") {
+		t.Error("wrong result; got:", i.Result(), s)
+	}
+
+	// requests to the enumerator are passed through
+	testcase := Label("number", Raise(Seek{2}).Then(Uint(BE, 2)))
+	enum := SeekableRead(strings.NewReader("0123456789"))
+	i = enum(testcase).(monad.IO)().(Iteratee)
+	if !i.IsDone() {
+		t.Error("should have succeeded; err:", i.Err())
+	} else if i.Result().(uint64) != 0x3233 {
+		t.Errorf("wrong result; got: %#v", i.Result())
+	}
+}
+
+func TestContext(t *testing.T) {
+	token  := Label("token", Many1([]byte(nil), NoneOf([]byte(" \r\n"))))
+	method := Context("method", token)
+	target := Context("target", token)
+	line   := Context("request-line", Seq(method, Byte(' '), target))
+
+	i, _ := line.Feed(Chunk(" / HTTP/1.1"))
+	if !i.IsStop() {
+		t.Error("should have failed")
+	} else if i.Err().Error() != "in request-line > method: expected token: no match" {
+		t.Error("wrong error; got:", i.Err())
+	}
+
+	i, _ = line.Feed(Chunk("GET\r\n"))
+	if !i.IsStop() {
+		t.Error("should have failed")
+	} else {
+		e, ok := i.Err().(InContext)
+		if !ok || len(e.Path) != 1 || e.Path[0] != "request-line" {
+			t.Errorf("wrong error; got: %#v", i.Err())
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	nl     := []byte("\n")
+	digits := Many1([]byte(nil), OneOf([]byte("0123456789")))
+	record := Recover(digits.ThenIgnore(Byte('\n')), SkipPast(nl))
+
+	i, s := record.Feed(Chunk("12\n3"))
+	if !i.IsDone() {
+		t.Error("should have succeeded; err:", i.Err())
+	} else if string(i.Result().([]byte)) != "12" || !eq(s, "3") {
+		t.Error("wrong result; got:", i.Result(), s)
+	}
+
+	i, s = record.Feed(Chunk("3x"))
+	i, s = i.Feed(Chunk("x\n4"))
+	if !i.IsDone() {
+		t.Error("should have succeeded; err:", i.Err())
+	} else if r, ok := i.Result().(Recovered); !ok || r.Err == nil {
+		t.Error("should have returned Recovered; got:", i.Result())
+	} else if !eq(s, "4") {
+		t.Error("consumed wrong; left:", s)
+	}
+}
+
+func TestManyRecover(t *testing.T) {
+	digits  := Many1([]byte(nil), OneOf([]byte("0123456789")))
+	record  := digits.ThenIgnore(Byte('\n'))
+	records := ManyRecover([][]byte(nil), record, SkipPast([]byte("\n\n")))
+
+	it := records
+	for _, chunk := range []string{"12\n3", "4\n5x6\n", "\n78\n", "9\n?"} {
+		it, _ = it.Feed(Chunk(chunk))
+	}
+	it, _ = it.Feed(End)
+	if !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+		return
+	}
+	p := it.Result().(Partial)
+	if fmt.Sprintf("%s", p.Results) != "[12 34 78 9]" {
+		t.Errorf("wrong results; got: %s", p.Results)
+	}
+	if len(p.Errs) != 2 {
+		t.Error("wrong errors; got:", p.Errs)
+	}
+}
+
+func TestGenericHelpers(t *testing.T) {
+	// "2:ab3:cde": a count followed by that many bytes, twice
+	digit := OneOf([]byte("0123456789")).Bind(func(d interface{}) Iteratee {
+		return Done(int(d.(byte) - '0'))
+	})
+	field := digit.ThenIgnore(Byte(':')).Bind(func(n interface{}) Iteratee {
+		return monad.Replicate(Return_, n.(int), Any).(Iteratee)
+	})
+	it := monad.Replicate(Return_, 2, field).(Iteratee)
+
+	r := parse(it, "2:ab3:cde")
+	if fmt.Sprintf("%c", r) != "[[a b] [c d e]]" {
+		t.Errorf("wrong result; got %c", r)
+	}
+}
+
+func TestEndErr(t *testing.T) {
+	boom := errors.New("boom")
+	feed := func(it Iteratee) Iteratee {
+		it, _ = it.Feed(Chunk("ab"))
+		it, _ = it.Feed(EndErr(boom))
+		return it
+	}
+
+	// broken input is not a mismatch: no alternative or label hides it
+	for _, it := range []Iteratee{
+		String("abc"),
+		Skip(2).Then(EndOfInput),
+		Many([]byte(nil), Any),
+		Choice(String("abc"), String("abd")),
+		Label("thing", String("abc")),
+		SkipPast([]byte("\n")),
+	} {
+		if it = feed(it); it.IsDone() || it.Err() != boom {
+			t.Error("should have failed with boom; got:", it.Err())
+		}
+	}
+
+	// unaffected if done before the end
+	if it := feed(String("ab")); !it.IsDone() {
+		t.Error("should have succeeded; err:", it.Err())
+	}
+	if s := EndErr(nil); s != End {
+		t.Error("EndErr(nil) should be End")
+	}
+}
@@ -12,10 +12,10 @@ const (
 )
 
 // stores a chunk of elements of the same (but arbitrary) type
-// XXX also store optional error on End
 type Stream struct {
 	slice interface{}
 	isEnd bool
+	err   error	// on End, why the input ended early
 
 	isBit bool
 	bitorder Endianness
@@ -25,8 +25,19 @@ type Stream struct {
 
 // constructors...
 
-var End   Stream = Stream{nil, true, false, LE, 0}
-var Empty Stream = Stream{nil, false, false, LE, 0}
+var End   Stream = Stream{nil, true, nil, false, LE, 0}
+var Empty Stream = Stream{nil, false, nil, false, LE, 0}
+
+// the end of input due to err, e.g. a failed read. an iteratee receiving it
+// should fail with err rather than treat the input as complete; the primitive
+// parsers do. like End, it must not be followed by further input.
+// NB: compare with IsEnd rather than == End to catch both.
+func EndErr(err error) Stream {
+	if err == nil {
+		return End
+	}
+	return Stream{nil, true, err, false, LE, 0}
+}
 
 func Chunk(slice interface{}) Stream {
 	v := reflect.ValueOf(slice)
@@ -38,7 +49,7 @@ func Chunk(slice interface{}) Stream {
 	if v.Len() == 0 {
 		return Empty
 	}
-	return Stream{slice, false, false, LE, 0}
+	return Stream{slice, false, nil, false, LE, 0}
 }
 var t_bytes reflect.Type = reflect.TypeOf([]byte(nil))
 
@@ -46,12 +57,30 @@ func BitChunk(slice []byte, bitorder Endianness, offset uint8) Stream {
 	if len(slice) == 0 {
 		return Empty
 	}
-	return Stream{slice, false, true, bitorder, offset}
+	return Stream{slice, false, nil, true, bitorder, offset}
 }
 
 
 // accesors...
 
+// End, with or without an error
+func (s *Stream) IsEnd() bool {
+	return s.isEnd
+}
+
+// the error the input ended with, nil if none
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// the error to fail with at the end of input: s's error, if any, or e
+func (s *Stream) errOr(e error) error {
+	if s.err != nil {
+		return s.err
+	}
+	return e
+}
+
 func (s *Stream) Slice() interface{} {
 	if s.isBit {
 		panic("Slice() called on bitstream")
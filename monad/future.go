@@ -0,0 +1,197 @@
+package monad
+
+import "context"
+
+
+// a computation running in its own goroutine. it settles exactly once, to a
+// Result; panics become errors as with Try. all operations return at once
+// except for Await.
+type Future struct {
+	p *promise
+}
+
+type promise struct {
+	done chan struct{}
+	r    Result		// valid once done is closed
+}
+
+func newFuture() Future {
+	return Future{&promise{done: make(chan struct{})}}
+}
+
+func (a Future) settle(r Result) {
+	a.p.r = r
+	close(a.p.done)
+}
+
+// start f in a goroutine
+func Go(f func() interface{}) Future {
+	a := newFuture()
+	go func() {a.settle(Try(f)())}()
+	return a
+}
+
+// as Go, but settles with ctx.Err() if ctx is done first. f is expected to
+// watch ctx itself; its result is dropped if it comes too late.
+func GoCtx(ctx context.Context, f func(context.Context) interface{}) Future {
+	inner := Go(func() interface{} {return f(ctx)})
+	a := newFuture()
+	go func() {
+		select {
+		case <-inner.p.done:
+			a.settle(inner.p.r)
+		case <-ctx.Done():
+			a.settle(Err(ctx.Err()))
+		}
+	}()
+	return a
+}
+
+// an already settled Future
+func ReturnFuture(x interface{}) Future {
+	a := newFuture()
+	a.settle(Ok(x))
+	return a
+}
+
+func ErrFuture(err error) Future {
+	a := newFuture()
+	a.settle(Err(err))
+	return a
+}
+
+// closed once a has settled
+func (a Future) Done() <-chan struct{} {
+	return a.p.done
+}
+
+// block until a has settled
+func (a Future) Await() Result {
+	<-a.p.done
+	return a.p.r
+}
+
+// as Await, but gives up with ctx.Err() when ctx is done. a keeps running.
+func (a Future) AwaitCtx(ctx context.Context) Result {
+	select {
+	case <-a.p.done:
+		return a.p.r
+	case <-ctx.Done():
+		return Err(ctx.Err())
+	}
+}
+
+func (a Future) Bind(f func(interface{}) Future) Future {
+	b := newFuture()
+	go func() {
+		r := a.Await()
+		if r.err != nil {
+			b.settle(r)
+			return
+		}
+		var next Future
+		r = Try(func() interface{} {next = f(r.value); return nil})()
+		if r.err != nil {
+			b.settle(r)
+			return
+		}
+		b.settle(next.Await())
+	}()
+	return b
+}
+
+func (a Future) Then(b Future) Future {
+	return a.Bind(func(interface{}) Future {return b})
+}
+
+func (a Future) ThenReturn(x interface{}) Future {
+	return a.Then(ReturnFuture(x))
+}
+
+func (a Future) Then_(b_ Monad) Monad {
+	return a.Then(b_.(Future))
+}
+
+func (a Future) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a Future) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) Future {return f_(x).(Future)}
+	return a.Bind(f)
+}
+
+
+// combinators...
+
+type settled struct {
+	i int
+	r Result
+}
+
+// report each future's Result on a channel, in the order they settle
+func collect(fs []Future) <-chan settled {
+	ch := make(chan settled, len(fs))	// nobody blocks if we stop early
+	for i, f := range fs {
+		go func(i int, f Future) {
+			ch <- settled{i, f.Await()}
+		}(i, f)
+	}
+	return ch
+}
+
+// yields the results of all fs as a []interface{}, in order. fails with the
+// first error to occur, without waiting for the rest.
+func All(fs ...Future) Future {
+	if len(fs) == 0 {
+		return ReturnFuture([]interface{}(nil))
+	}
+	a := newFuture()
+	go func() {
+		results := make([]interface{}, len(fs))
+		ch := collect(fs)
+		for range fs {
+			s := <-ch
+			if s.r.err != nil {
+				a.settle(s.r)
+				return
+			}
+			results[s.i] = s.r.value
+		}
+		a.settle(Ok(results))
+	}()
+	return a
+}
+
+// settles like the first of fs to settle. panics if fs is empty.
+func Race(fs ...Future) Future {
+	if len(fs) == 0 {
+		panic("Race: no futures")
+	}
+	a := newFuture()
+	go func() {
+		a.settle((<-collect(fs)).r)
+	}()
+	return a
+}
+
+// run the given actions concurrently, at most n at a time (no limit if
+// n <= 0), and yield their results as with All.
+func Parallel(n int, ms ...IO) Future {
+	var sem chan struct{}
+	if n > 0 {
+		sem = make(chan struct{}, n)
+	}
+	fs := make([]Future, len(ms))
+	for i, m := range ms {
+		m := m
+		fs[i] = Go(func() interface{} {
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() {<-sem}()
+			}
+			return m()
+		})
+	}
+	return All(fs...)
+}
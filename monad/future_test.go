@@ -0,0 +1,106 @@
+package monad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+
+func slow(d time.Duration, x interface{}) Future {
+	return Go(func() interface{} {
+		time.Sleep(d)
+		return x
+	})
+}
+
+func ExampleFuture() {
+	sum := slow(10 * time.Millisecond, 1).Bind(func(x interface{}) Future {
+		return slow(10 * time.Millisecond, x.(int) + 1)
+	})
+	fmt.Println(sum.Await().Get())
+	// Output: 2 <nil>
+}
+
+func ExampleAll() {
+	a := All(slow(20 * time.Millisecond, "a"), slow(0, "b"), ReturnFuture("c"))
+	fmt.Println(a.Await().Get())
+	// Output: [a b c] <nil>
+}
+
+func ExampleRace() {
+	a := Race(slow(time.Second, "slow"), slow(0, "fast"))
+	fmt.Println(a.Await().Get())
+	// Output: fast <nil>
+}
+
+func TestAllFails(t *testing.T) {
+	boom := errors.New("boom")
+	start := time.Now()
+	a := All(slow(time.Second, 1), ErrFuture(boom))
+	if r := a.Await(); r != Err(boom) {
+		t.Error("wrong result; got:", r)
+	}
+	if time.Since(start) > 500 * time.Millisecond {
+		t.Error("All should not wait after an error")
+	}
+}
+
+func TestFuturePanic(t *testing.T) {
+	a := Go(func() interface{} {panic("oops")})
+	if _, err := a.Await().Get(); err == nil || err.Error() != "panic: oops" {
+		t.Error("wrong error; got:", err)
+	}
+
+	b := ReturnFuture(1).Bind(func(interface{}) Future {panic("oops")})
+	if _, err := b.Await().Get(); err == nil {
+		t.Error("panic in Bind should have been caught")
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var running, max int32
+	job := func(x int) IO {
+		return func() interface{} {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return x * x
+		}
+	}
+
+	r, err := Parallel(2, job(1), job(2), job(3), job(4), job(5)).Await().Get()
+	if err != nil || fmt.Sprint(r) != "[1 4 9 16 25]" {
+		t.Error("wrong result; got:", r, err)
+	}
+	if max > 2 {
+		t.Error("too many at once:", max)
+	}
+}
+
+func TestFutureContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := GoCtx(ctx, func(ctx context.Context) interface{} {
+		<-ctx.Done()
+		time.Sleep(time.Second)	// too late
+		return "done"
+	})
+	b := slow(time.Second, "b")
+
+	cancel()
+	if r := a.Await(); r != Err(context.Canceled) {
+		t.Error("GoCtx: wrong result; got:", r)
+	}
+	if r := b.AwaitCtx(ctx); r != Err(context.Canceled) {
+		t.Error("AwaitCtx: wrong result; got:", r)
+	}
+}
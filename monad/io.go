@@ -3,6 +3,12 @@ package monad
 
 type IO func() interface{}
 
+func ReturnIO(x interface{}) IO {
+	return func() interface{} {
+		return x
+	}
+}
+
 func (a IO) Then(b IO) IO {
 	return func() interface{} {
 		a(); return b()
@@ -34,3 +40,13 @@ func (a IO) Bind_(f_ func(interface{}) Monad) Monad {
 	f := func(x interface{}) IO {return f_(x).(IO)}
 	return a.Bind(f)
 }
+
+// run a over and over, in constant stack space. never returns, except by
+// panicking.
+func (a IO) Forever() IO {
+	return func() interface{} {
+		for {
+			a()
+		}
+	}
+}
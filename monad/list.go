@@ -0,0 +1,63 @@
+package monad
+
+
+// nondeterministic computations: a list of all possible results
+type List []interface{}
+
+func ReturnList(x interface{}) List {
+	return List{x}
+}
+
+// no results
+var MZero List = nil
+
+// the results of a and b
+func (a List) MPlus(b List) List {
+	return append(a[:len(a):len(a)], b...)
+}
+
+// cut off computations where cond does not hold
+func Guard(cond bool) List {
+	if !cond {
+		return MZero
+	}
+	return List{nil}
+}
+
+
+// monad instance...
+
+// flatMap
+func (a List) Bind(f func(interface{}) List) List {
+	var r List
+	for _, x := range a {
+		r = append(r, f(x)...)
+	}
+	return r
+}
+
+// b for each result of a
+func (a List) Then(b List) List {
+	var r List
+	for range a {
+		r = append(r, b...)
+	}
+	return r
+}
+
+func (a List) ThenReturn(x interface{}) List {
+	return a.Then(ReturnList(x))
+}
+
+func (a List) Then_(b_ Monad) Monad {
+	return a.Then(b_.(List))
+}
+
+func (a List) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a List) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) List {return f_(x).(List)}
+	return a.Bind(f)
+}
@@ -0,0 +1,49 @@
+package monad
+
+import "fmt"
+
+
+func upto(n int) List {
+	var r List
+	for i := 1; i <= n; i++ {
+		r = append(r, i)
+	}
+	return r
+}
+
+func ExampleGuard() {
+	triples := upto(20).Bind(func(a interface{}) List {
+		return upto(20).Bind(func(b interface{}) List {
+			return upto(20).Bind(func(c interface{}) List {
+				x, y, z := a.(int), b.(int), c.(int)
+				return Guard(x < y && x*x + y*y == z*z).
+				       ThenReturn([3]int{x, y, z})
+			})
+		})
+	})
+	fmt.Println(triples)
+	// Output: [[3 4 5] [5 12 13] [6 8 10] [8 15 17] [9 12 15] [12 16 20]]
+}
+
+// all ways to split s into non-empty chunks
+func splits(s string) List {
+	if s == "" {
+		return ReturnList([]string(nil))
+	}
+	return upto(len(s)).Bind(func(n interface{}) List {
+		head := s[:n.(int)]
+		return splits(s[n.(int):]).Bind(func(tail interface{}) List {
+			return ReturnList(append([]string{head}, tail.([]string)...))
+		})
+	})
+}
+
+func ExampleList() {
+	fmt.Printf("%q\n", splits("abc"))
+	fmt.Println(List{1, 2}.Then(List{"a", "b"}))
+	fmt.Println(List{1, 2}.MPlus(MZero).MPlus(List{3}))
+	// Output:
+	// [["a" "b" "c"] ["a" "bc"] ["ab" "c"] ["abc"]]
+	// [a b a b]
+	// [1 2 3]
+}
@@ -0,0 +1,69 @@
+package monad
+
+
+// an optional value
+type Maybe struct {
+	value interface{}
+	ok    bool
+}
+
+var Nothing Maybe = Maybe{nil, false}
+
+func Just(x interface{}) Maybe {
+	return Maybe{x, true}
+}
+
+func (m Maybe) IsJust() bool {return m.ok}
+
+// the value and whether there is one
+func (m Maybe) Get() (interface{}, bool) {return m.value, m.ok}
+
+// the value or def if there is none
+func (m Maybe) FromMaybe(def interface{}) interface{} {
+	if !m.ok {
+		return def
+	}
+	return m.value
+}
+
+// m or, if it is Nothing, b
+func (m Maybe) OrElse(b Maybe) Maybe {
+	if !m.ok {
+		return b
+	}
+	return m
+}
+
+
+// monad instance...
+
+func (a Maybe) Bind(f func(interface{}) Maybe) Maybe {
+	if !a.ok {
+		return Nothing
+	}
+	return f(a.value)
+}
+
+func (a Maybe) Then(b Maybe) Maybe {
+	if !a.ok {
+		return Nothing
+	}
+	return b
+}
+
+func (a Maybe) ThenReturn(x interface{}) Maybe {
+	return a.Then(Just(x))
+}
+
+func (a Maybe) Then_(b_ Monad) Monad {
+	return a.Then(b_.(Maybe))
+}
+
+func (a Maybe) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a Maybe) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) Maybe {return f_(x).(Maybe)}
+	return a.Bind(f)
+}
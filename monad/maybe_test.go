@@ -0,0 +1,45 @@
+package monad
+
+import "fmt"
+
+
+var config = map[string]interface{}{
+	"server": map[string]interface{}{
+		"port": 8080,
+	},
+}
+
+func lookup(key string) func(interface{}) Maybe {
+	return func(m interface{}) Maybe {
+		x, ok := m.(map[string]interface{})[key]
+		if !ok {
+			return Nothing
+		}
+		return Just(x)
+	}
+}
+
+func ExampleMaybe() {
+	port := Just(config).Bind(lookup("server")).Bind(lookup("port"))
+	fmt.Println(port.FromMaybe(80))
+
+	host := Just(config).Bind(lookup("server")).Bind(lookup("host"))
+	fmt.Println(host.FromMaybe("localhost"))
+
+	proxy := Just(config).Bind(lookup("proxy")).Bind(lookup("host"))
+	fmt.Println(proxy.OrElse(host).OrElse(Just("none")).FromMaybe(nil))
+	// Output:
+	// 8080
+	// localhost
+	// none
+}
+
+func ExampleMaybe_Then() {
+	fmt.Println(Just(1).Then(Just(2)).FromMaybe(0))
+	fmt.Println(Nothing.Then(Just(2)).FromMaybe(0))
+	fmt.Println(Just(1).ThenReturn_(3).(Maybe).Get())
+	// Output:
+	// 2
+	// 0
+	// 3 true
+}
@@ -46,6 +46,9 @@ package monad
 //  - def. Then:        a.Then(b) ~ a.Bind({return b})
 //  - def. ThenReturn:  a.ThenReturn(x) ~ a.Then(Return(x))
 //
+// package typed offers a type-parameterized IO for code that prefers
+// compile-time checks over these assertions.
+//
 type Monad interface {
 	Bind_(func(interface{}) Monad) Monad	// (>>=)
 	Then_(Monad) Monad						// (>>)
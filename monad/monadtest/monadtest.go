@@ -0,0 +1,71 @@
+// Property tests for Monad instances
+package monadtest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// a Monad instance to check, given by its Return, a notion of equivalence,
+// and generators for random values, monadic values, and functions.
+type Laws struct {
+	Return func(interface{}) monad.Monad
+	Equal  func(a, b monad.Monad) bool
+
+	Value  func(*rand.Rand) interface{}
+	Monad  func(*rand.Rand) monad.Monad
+	Func   func(*rand.Rand) func(interface{}) monad.Monad
+
+	N      int		// number of trials per law; <= 0 means 100
+	Seed   int64
+}
+
+// check the monad laws (cf. monad.Monad) on randomly generated arguments.
+// reports the first violation of each law.
+func Check(t testing.TB, l Laws) {
+	t.Helper()
+	n := l.N
+	if n <= 0 {
+		n = 100
+	}
+	rnd := rand.New(rand.NewSource(l.Seed))
+
+	check := func(law string, trial func() (monad.Monad, monad.Monad)) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			lhs, rhs := trial()
+			if !l.Equal(lhs, rhs) {
+				t.Errorf("%s violated in trial %d", law, i)
+				return
+			}
+		}
+	}
+
+	check("left identity", func() (monad.Monad, monad.Monad) {
+		x, f := l.Value(rnd), l.Func(rnd)
+		return l.Return(x).Bind_(f), f(x)
+	})
+	check("right identity", func() (monad.Monad, monad.Monad) {
+		a := l.Monad(rnd)
+		return a.Bind_(l.Return), a
+	})
+	check("associativity", func() (monad.Monad, monad.Monad) {
+		a, f, g := l.Monad(rnd), l.Func(rnd), l.Func(rnd)
+		return a.Bind_(f).Bind_(g),
+		       a.Bind_(func(x interface{}) monad.Monad {
+		           return f(x).Bind_(g)
+		       })
+	})
+	check("definition of Then", func() (monad.Monad, monad.Monad) {
+		a, b := l.Monad(rnd), l.Monad(rnd)
+		return a.Then_(b),
+		       a.Bind_(func(interface{}) monad.Monad {return b})
+	})
+	check("definition of ThenReturn", func() (monad.Monad, monad.Monad) {
+		a, x := l.Monad(rnd), l.Value(rnd)
+		return a.ThenReturn_(x), a.Then_(l.Return(x))
+	})
+}
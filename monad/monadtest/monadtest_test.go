@@ -0,0 +1,408 @@
+package monadtest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/pesco/go/monad"
+)
+
+
+// IO actions are equivalent if they return the same and log the same
+var log []int
+
+func logging(n int, x interface{}) monad.IO {
+	return func() interface{} {
+		log = append(log, n)
+		return x
+	}
+}
+
+func run(m monad.Monad) (interface{}, []int) {
+	log = nil
+	x := m.(monad.IO)()
+	return x, log
+}
+
+func equalIO(a, b monad.Monad) bool {
+	x, xlog := run(a)
+	y, ylog := run(b)
+	return x == y && reflect.DeepEqual(xlog, ylog)
+}
+
+var laws = Laws{
+	Return: func(x interface{}) monad.Monad {return monad.ReturnIO(x)},
+	Equal:  equalIO,
+	Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+	Monad:  func(rnd *rand.Rand) monad.Monad {
+		return logging(rnd.Intn(10), rnd.Intn(10))
+	},
+	Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+		n, k := rnd.Intn(10), rnd.Intn(10)
+		return func(x interface{}) monad.Monad {
+			return logging(n, x.(int) * k)
+		}
+	},
+}
+
+func TestIO(t *testing.T) {
+	Check(t, laws)
+}
+
+// records failures instead of reporting them
+type recorder struct {
+	testing.TB
+	failed bool
+}
+func (r *recorder) Errorf(string, ...interface{}) {r.failed = true}
+func (r *recorder) Helper() {}
+
+func TestViolation(t *testing.T) {
+	// a Return with a side effect violates the identity laws
+	bad := laws
+	bad.Return = func(x interface{}) monad.Monad {return logging(-1, x)}
+
+	r := &recorder{TB: t}
+	Check(r, bad)
+	if !r.failed {
+		t.Error("should have found a violation")
+	}
+}
+
+func randomMaybe(rnd *rand.Rand, x interface{}) monad.Maybe {
+	if rnd.Intn(4) == 0 {
+		return monad.Nothing
+	}
+	return monad.Just(x)
+}
+
+func TestMaybe(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.Just(x)},
+		Equal:  func(a, b monad.Monad) bool {return a == b},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			return randomMaybe(rnd, rnd.Intn(10))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k, nothing := rnd.Intn(10), rnd.Intn(4) == 0
+			return func(x interface{}) monad.Monad {
+				if nothing {
+					return monad.Nothing
+				}
+				return monad.Just(x.(int) + k)
+			}
+		},
+	})
+}
+
+var errRandom = errors.New("random")
+
+func randomResult(rnd *rand.Rand, x interface{}) monad.Result {
+	if rnd.Intn(4) == 0 {
+		return monad.Err(errRandom)
+	}
+	return monad.Ok(x)
+}
+
+func TestResult(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.Ok(x)},
+		Equal:  func(a, b monad.Monad) bool {return a == b},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			return randomResult(rnd, rnd.Intn(10))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k, fail := rnd.Intn(10), rnd.Intn(4) == 0
+			return func(x interface{}) monad.Monad {
+				if fail {
+					return monad.Err(errRandom)
+				}
+				return monad.Ok(x.(int) + k)
+			}
+		},
+	})
+}
+
+func TestIOResult(t *testing.T) {
+	run := func(m monad.Monad) (monad.Result, []int) {
+		log = nil
+		r := m.(monad.IOResult)()
+		return r, log
+	}
+	logging := func(n int, r monad.Result) monad.IOResult {
+		return func() monad.Result {
+			log = append(log, n)
+			return r
+		}
+	}
+
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.OkIO(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			x, xlog := run(a)
+			y, ylog := run(b)
+			return x == y && reflect.DeepEqual(xlog, ylog)
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			return logging(rnd.Intn(10), randomResult(rnd, rnd.Intn(10)))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			n, k, fail := rnd.Intn(10), rnd.Intn(10), rnd.Intn(4) == 0
+			return func(x interface{}) monad.Monad {
+				if fail {
+					return logging(n, monad.Err(errRandom))
+				}
+				return logging(n, monad.Ok(x.(int) + k))
+			}
+		},
+	})
+}
+
+func TestState(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.ReturnState(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			for s := 0; s < 3; s++ {
+				x, xs := a.(monad.State)(s)
+				y, ys := b.(monad.State)(s)
+				if x != y || xs != ys {
+					return false
+				}
+			}
+			return true
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			switch k := rnd.Intn(10); rnd.Intn(3) {
+			case 0:  return monad.Get
+			case 1:  return monad.Put(k).ThenReturn(k)
+			default: return monad.ReturnState(k)
+			}
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k := rnd.Intn(10)
+			return func(x interface{}) monad.Monad {
+				return monad.Modify(func(s interface{}) interface{} {
+					return s.(int) + x.(int)
+				}).ThenReturn(x.(int) * k)
+			}
+		},
+	})
+}
+
+func TestReader(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.ReturnReader(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			for env := 0; env < 3; env++ {
+				if a.(monad.Reader)(env) != b.(monad.Reader)(env) {
+					return false
+				}
+			}
+			return true
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			if rnd.Intn(2) == 0 {
+				return monad.Ask
+			}
+			return monad.ReturnReader(rnd.Intn(10))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k := rnd.Intn(10)
+			return func(x interface{}) monad.Monad {
+				return monad.Ask.Bind(func(env interface{}) monad.Reader {
+					return monad.ReturnReader(env.(int) * k + x.(int))
+				})
+			}
+		},
+	})
+}
+
+func TestWriter(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.ReturnWriter(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			x, xlog := a.(monad.Writer).Run()
+			y, ylog := b.(monad.Writer).Run()
+			return x == y && reflect.DeepEqual(xlog, ylog)
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			msg := fmt.Sprint(rnd.Intn(10))
+			return monad.Tell(monad.Lines{msg}).ThenReturn(rnd.Intn(10))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k := rnd.Intn(10)
+			return func(x interface{}) monad.Monad {
+				msg := fmt.Sprint(x, k)
+				return monad.Tell(monad.Lines{msg}).ThenReturn(x.(int) + k)
+			}
+		},
+	})
+}
+
+func TestList(t *testing.T) {
+	list := func(rnd *rand.Rand) monad.List {
+		var l monad.List
+		for n := rnd.Intn(4); n > 0; n-- {
+			l = append(l, rnd.Intn(10))
+		}
+		return l
+	}
+
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.ReturnList(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			return fmt.Sprint(a) == fmt.Sprint(b)
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {return list(rnd)},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			l := list(rnd)
+			return func(x interface{}) monad.Monad {
+				return l.Bind(func(y interface{}) monad.List {
+					return monad.List{x.(int) * y.(int)}
+				})
+			}
+		},
+	})
+}
+
+// transformers over IO, compared by running them against the same input
+
+var ioReturn = func(x interface{}) monad.Monad {return monad.ReturnIO(x)}
+
+func TestStateT(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {
+			return monad.ReturnStateT(ioReturn, x)
+		},
+		Equal:  func(a, b monad.Monad) bool {
+			for s := 0; s < 3; s++ {
+				x, xlog := run(a.(monad.StateT).Run(s))
+				y, ylog := run(b.(monad.StateT).Run(s))
+				if x != y || !reflect.DeepEqual(xlog, ylog) {
+					return false
+				}
+			}
+			return true
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			switch n, k := rnd.Intn(10), rnd.Intn(10); rnd.Intn(3) {
+			case 0:  return monad.GetT(ioReturn)
+			case 1:  return monad.PutT(ioReturn, k).ThenReturn(k)
+			default: return monad.LiftStateT(ioReturn, logging(n, k))
+			}
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			n, k := rnd.Intn(10), rnd.Intn(10)
+			return func(x interface{}) monad.Monad {
+				add := func(s interface{}) interface{} {return s.(int) + x.(int)}
+				return monad.ModifyT(ioReturn, add).
+				       Then(monad.LiftStateT(ioReturn, logging(n, x.(int) * k)))
+			}
+		},
+	})
+}
+
+func TestReaderT(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {
+			return monad.ReturnReaderT(ioReturn, x)
+		},
+		Equal:  func(a, b monad.Monad) bool {
+			for env := 0; env < 3; env++ {
+				x, xlog := run(a.(monad.ReaderT).Run(env))
+				y, ylog := run(b.(monad.ReaderT).Run(env))
+				if x != y || !reflect.DeepEqual(xlog, ylog) {
+					return false
+				}
+			}
+			return true
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			if rnd.Intn(2) == 0 {
+				return monad.AskT(ioReturn)
+			}
+			return monad.LiftReaderT(ioReturn, logging(rnd.Intn(10), rnd.Intn(10)))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			n, k := rnd.Intn(10), rnd.Intn(10)
+			return func(x interface{}) monad.Monad {
+				return monad.AskT(ioReturn).Bind(func(env interface{}) monad.ReaderT {
+					y := env.(int) * k + x.(int)
+					return monad.LiftReaderT(ioReturn, logging(n, y))
+				})
+			}
+		},
+	})
+}
+
+func TestErrorT(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {
+			return monad.ReturnErrorT(ioReturn, x)
+		},
+		Equal:  func(a, b monad.Monad) bool {
+			x, xlog := run(a.(monad.ErrorT).Run())
+			y, ylog := run(b.(monad.ErrorT).Run())
+			return x == y && reflect.DeepEqual(xlog, ylog)
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			n, k := rnd.Intn(10), rnd.Intn(10)
+			if rnd.Intn(4) == 0 {
+				return monad.LiftErrorT(ioReturn, logging(n, k)).
+				       Then(monad.ThrowT(ioReturn, errRandom))
+			}
+			return monad.LiftErrorT(ioReturn, logging(n, k))
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			n, k, fail := rnd.Intn(10), rnd.Intn(10), rnd.Intn(4) == 0
+			return func(x interface{}) monad.Monad {
+				if fail {
+					return monad.ThrowT(ioReturn, errRandom)
+				}
+				return monad.LiftErrorT(ioReturn, logging(n, x.(int) + k))
+			}
+		},
+	})
+}
+
+func TestFuture(t *testing.T) {
+	Check(t, Laws{
+		Return: func(x interface{}) monad.Monad {return monad.ReturnFuture(x)},
+		Equal:  func(a, b monad.Monad) bool {
+			return a.(monad.Future).Await() == b.(monad.Future).Await()
+		},
+		Value:  func(rnd *rand.Rand) interface{} {return rnd.Intn(10)},
+		Monad:  func(rnd *rand.Rand) monad.Monad {
+			r := randomResult(rnd, rnd.Intn(10))
+			return monad.Go(func() interface{} {
+				x, err := r.Get()
+				if err != nil {
+					panic(err)
+				}
+				return x
+			})
+		},
+		Func:   func(rnd *rand.Rand) func(interface{}) monad.Monad {
+			k, fail := rnd.Intn(10), rnd.Intn(4) == 0
+			return func(x interface{}) monad.Monad {
+				if fail {
+					return monad.ErrFuture(errRandom)
+				}
+				return monad.Go(func() interface{} {return x.(int) + k})
+			}
+		},
+	})
+}
@@ -0,0 +1,53 @@
+package monad
+
+
+// a computation that reads a shared environment
+type Reader func(env interface{}) interface{}
+
+func ReturnReader(x interface{}) Reader {
+	return func(interface{}) interface{} {
+		return x
+	}
+}
+
+// return the environment
+var Ask Reader = func(env interface{}) interface{} {
+	return env
+}
+
+// run a in the environment f(env)
+func Local(f func(interface{}) interface{}, a Reader) Reader {
+	return func(env interface{}) interface{} {
+		return a(f(env))
+	}
+}
+
+
+// monad instance...
+
+func (a Reader) Bind(f func(interface{}) Reader) Reader {
+	return func(env interface{}) interface{} {
+		return f(a(env))(env)
+	}
+}
+
+func (a Reader) Then(b Reader) Reader {
+	return b	// a cannot have any effect
+}
+
+func (a Reader) ThenReturn(x interface{}) Reader {
+	return a.Then(ReturnReader(x))
+}
+
+func (a Reader) Then_(b_ Monad) Monad {
+	return a.Then(b_.(Reader))
+}
+
+func (a Reader) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a Reader) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) Reader {return f_(x).(Reader)}
+	return a.Bind(f)
+}
@@ -0,0 +1,31 @@
+package monad
+
+import "fmt"
+
+
+type server struct {
+	host   string
+	port   int
+	prefix string
+}
+
+var url Reader = Ask.Bind(func(e interface{}) Reader {
+	return ReturnReader(fmt.Sprintf("http://%s:%d%s",
+	                    e.(server).host, e.(server).port, e.(server).prefix))
+})
+
+func ExampleReader() {
+	api := Local(func(e interface{}) interface{} {
+		e_ := e.(server)
+		e_.prefix += "/api"
+		return e_
+	}, url)
+
+	both := url.Bind(func(u interface{}) Reader {
+		return api.Bind(func(a interface{}) Reader {
+			return ReturnReader([]interface{}{u, a})
+		})
+	})
+	fmt.Println(both(server{"localhost", 8080, "/v1"}))
+	// Output: [http://localhost:8080/v1 http://localhost:8080/v1/api]
+}
@@ -0,0 +1,223 @@
+package monad
+
+import (
+	"fmt"
+	"time"
+)
+
+
+// a value or an error
+type Result struct {
+	value interface{}
+	err   error
+}
+
+func Ok(x interface{}) Result {
+	return Result{x, nil}
+}
+
+// NB: Err(nil) is Ok(nil)
+func Err(e error) Result {
+	return Result{nil, e}
+}
+
+func (r Result) IsOk() bool {return r.err == nil}
+
+func (r Result) Get() (interface{}, error) {return r.value, r.err}
+
+// handle an error by continuing with f
+func (r Result) Catch(f func(error) Result) Result {
+	if r.err == nil {
+		return r
+	}
+	return f(r.err)
+}
+
+// replace an error with f(err)
+func (r Result) MapErr(f func(error) error) Result {
+	if r.err == nil {
+		return r
+	}
+	return Err(f(r.err))
+}
+
+
+// monad instance...
+
+// short-circuits on the first error
+func (a Result) Bind(f func(interface{}) Result) Result {
+	if a.err != nil {
+		return a
+	}
+	return f(a.value)
+}
+
+func (a Result) Then(b Result) Result {
+	if a.err != nil {
+		return a
+	}
+	return b
+}
+
+func (a Result) ThenReturn(x interface{}) Result {
+	return a.Then(Ok(x))
+}
+
+func (a Result) Then_(b_ Monad) Monad {
+	return a.Then(b_.(Result))
+}
+
+func (a Result) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a Result) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) Result {return f_(x).(Result)}
+	return a.Bind(f)
+}
+
+
+// an IO action that can fail
+type IOResult func() Result
+
+func OkIO(x interface{}) IOResult {
+	return func() Result {return Ok(x)}
+}
+
+func ErrIO(e error) IOResult {
+	return func() Result {return Err(e)}
+}
+
+// an action that cannot fail
+func LiftIO(a IO) IOResult {
+	return func() Result {return Ok(a())}
+}
+
+// an action that fails instead of panicking. panics with a value that is not
+// an error are wrapped in one.
+func Try(a IO) IOResult {
+	return func() (r Result) {
+		defer func() {
+			if p := recover(); p != nil {
+				err, ok := p.(error)
+				if !ok {
+					err = fmt.Errorf("panic: %v", p)
+				}
+				r = Err(err)
+			}
+		}()
+		return Ok(a())
+	}
+}
+
+// the IO action yielding a's Result
+func (a IOResult) IO() IO {
+	return func() interface{} {return a()}
+}
+
+func (a IOResult) Catch(f func(error) IOResult) IOResult {
+	return func() Result {
+		r := a()
+		if r.err == nil {
+			return r
+		}
+		return f(r.err)()
+	}
+}
+
+func (a IOResult) MapErr(f func(error) error) IOResult {
+	return func() Result {return a().MapErr(f)}
+}
+
+// run cleanup after a, even if a fails or panics. a's error takes precedence;
+// cleanup's only counts if a succeeded.
+func (a IOResult) Finally(cleanup IOResult) IOResult {
+	return func() (r Result) {
+		defer func() {
+			if c := cleanup(); c.err != nil && r.err == nil {
+				r = c
+			}
+		}()
+		return a()
+	}
+}
+
+// acquire a resource, use it, and release it, whatever happens during use.
+// release is not run if acquire fails.
+func Bracket(acquire IOResult, release func(interface{}) IOResult,
+             use func(interface{}) IOResult) IOResult {
+	return acquire.Bind(func(x interface{}) IOResult {
+		run := IOResult(func() Result {return use(x)()})
+		return run.Finally(release(x))
+	})
+}
+
+// run a until it succeeds, at most n times (but at least once), sleeping
+// backoff(i) after the i-th failed attempt, counting from 0. yields the last
+// error if all fail. a nil backoff does not sleep.
+func (a IOResult) Retry(n int, backoff func(int) time.Duration) IOResult {
+	return func() Result {
+		r := a()
+		for i := 0; r.err != nil && i < n-1; i++ {
+			if backoff != nil {
+				time.Sleep(backoff(i))
+			}
+			r = a()
+		}
+		return r
+	}
+}
+
+// run a over and over, in constant stack space, until it fails. yields the
+// error.
+func (a IOResult) Forever() IOResult {
+	return func() Result {
+		for {
+			if r := a(); r.err != nil {
+				return r
+			}
+		}
+	}
+}
+
+// a backoff for Retry that doubles from d
+func ExpBackoff(d time.Duration) func(int) time.Duration {
+	return func(i int) time.Duration {return d << uint(i)}
+}
+
+// short-circuits on the first error
+func (a IOResult) Bind(f func(interface{}) IOResult) IOResult {
+	return func() Result {
+		r := a()
+		if r.err != nil {
+			return r
+		}
+		return f(r.value)()
+	}
+}
+
+func (a IOResult) Then(b IOResult) IOResult {
+	return func() Result {
+		if r := a(); r.err != nil {
+			return r
+		}
+		return b()
+	}
+}
+
+func (a IOResult) ThenReturn(x interface{}) IOResult {
+	return a.Then(OkIO(x))
+}
+
+func (a IOResult) Then_(b_ Monad) Monad {
+	return a.Then(b_.(IOResult))
+}
+
+func (a IOResult) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a IOResult) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) IOResult {return f_(x).(IOResult)}
+	return a.Bind(f)
+}
@@ -0,0 +1,125 @@
+package monad
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+
+func atoi(x interface{}) Result {
+	n, err := strconv.Atoi(x.(string))
+	if err != nil {
+		return Err(err)
+	}
+	return Ok(n)
+}
+
+func half(x interface{}) Result {
+	if x.(int) % 2 != 0 {
+		return Err(fmt.Errorf("%d is odd", x))
+	}
+	return Ok(x.(int) / 2)
+}
+
+func ExampleResult() {
+	fmt.Println(Ok("42").Bind(atoi).Bind(half).Get())
+	fmt.Println(Ok("43").Bind(atoi).Bind(half).Get())
+	fmt.Println(Ok("4x").Bind(atoi).Bind(half).Get())
+	// Output:
+	// 21 <nil>
+	// <nil> 43 is odd
+	// <nil> strconv.Atoi: parsing "4x": invalid syntax
+}
+
+func ExampleResult_Catch() {
+	zero := func(error) Result {return Ok(0)}
+	fmt.Println(Ok("43").Bind(atoi).Bind(half).Catch(zero).Get())
+
+	wrap := func(err error) error {return fmt.Errorf("config: %v", err)}
+	fmt.Println(Ok("43").Bind(atoi).Bind(half).MapErr(wrap).Get())
+	// Output:
+	// 0 <nil>
+	// <nil> config: 43 is odd
+}
+
+func ExampleTry() {
+	fail := IO(func() interface{} {
+		panic(errors.New("disk on fire"))
+	})
+	var m IOResult = LiftIO(hallo).Then(Try(fail)).Then(LiftIO(welt))
+	fmt.Println(m().Get())
+	// Output: hallo <nil> disk on fire
+}
+
+func ExampleIOResult_Catch() {
+	var m IOResult = ErrIO(errors.New("oops")).Catch(func(err error) IOResult {
+		return LiftIO(print(err.Error() + "\n"))
+	})
+	m()
+	// Output: oops
+}
+
+func ExampleBracket() {
+	open := LiftIO(print("open\n").ThenReturn("file"))
+	close := func(x interface{}) IOResult {
+		return LiftIO(print("close " + x.(string) + "\n"))
+	}
+	parse := func(x interface{}) IOResult {
+		return ErrIO(fmt.Errorf("cannot parse %s", x))
+	}
+	fmt.Println(Bracket(open, close, parse)().Get())
+	// Output:
+	// open
+	// close file
+	// <nil> cannot parse file
+}
+
+func ExampleIOResult_Retry() {
+	n := 0
+	flaky := IOResult(func() Result {
+		if n++; n < 3 {
+			return Err(fmt.Errorf("attempt %d failed", n))
+		}
+		return Ok(n)
+	})
+	fmt.Println(flaky.Retry(2, nil)().Get())
+	fmt.Println(flaky.Retry(2, ExpBackoff(time.Millisecond))().Get())
+	// Output:
+	// <nil> attempt 2 failed
+	// 3 <nil>
+}
+
+func TestFinally(t *testing.T) {
+	ran := false
+	cleanup := IOResult(func() Result {ran = true; return Err(errors.New("cleanup"))})
+
+	// a's error wins
+	r := ErrIO(errors.New("a")).Finally(cleanup)()
+	if _, err := r.Get(); !ran || err.Error() != "a" {
+		t.Error("wrong result; got:", r, ran)
+	}
+
+	// cleanup's error counts if a succeeded
+	ran = false
+	r = OkIO(1).Finally(cleanup)()
+	if _, err := r.Get(); !ran || err.Error() != "cleanup" {
+		t.Error("wrong result; got:", r, ran)
+	}
+
+	// cleanup runs on a panic, which goes on
+	ran = false
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("should have panicked")
+			}
+		}()
+		IOResult(func() Result {panic("oops")}).Finally(cleanup)()
+	}()
+	if !ran {
+		t.Error("cleanup did not run on panic")
+	}
+}
@@ -0,0 +1,76 @@
+package monad
+
+
+// a computation threading a state; returns its result and the new state
+type State func(s interface{}) (interface{}, interface{})
+
+func ReturnState(x interface{}) State {
+	return func(s interface{}) (interface{}, interface{}) {
+		return x, s
+	}
+}
+
+// return the state
+var Get State = func(s interface{}) (interface{}, interface{}) {
+	return s, s
+}
+
+// replace the state
+func Put(s interface{}) State {
+	return func(interface{}) (interface{}, interface{}) {
+		return nil, s
+	}
+}
+
+// replace the state with f(state)
+func Modify(f func(interface{}) interface{}) State {
+	return func(s interface{}) (interface{}, interface{}) {
+		return nil, f(s)
+	}
+}
+
+// run a, returning only the result
+func (a State) Eval(s interface{}) interface{} {
+	x, _ := a(s)
+	return x
+}
+
+// run a, returning only the final state
+func (a State) Exec(s interface{}) interface{} {
+	_, s = a(s)
+	return s
+}
+
+
+// monad instance...
+
+func (a State) Bind(f func(interface{}) State) State {
+	return func(s interface{}) (interface{}, interface{}) {
+		x, s := a(s)
+		return f(x)(s)
+	}
+}
+
+func (a State) Then(b State) State {
+	return func(s interface{}) (interface{}, interface{}) {
+		_, s = a(s)
+		return b(s)
+	}
+}
+
+func (a State) ThenReturn(x interface{}) State {
+	return a.Then(ReturnState(x))
+}
+
+func (a State) Then_(b_ Monad) Monad {
+	return a.Then(b_.(State))
+}
+
+func (a State) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a State) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) State {return f_(x).(State)}
+	return a.Bind(f)
+}
@@ -0,0 +1,41 @@
+package monad
+
+import "fmt"
+
+
+// a stack machine: the state is a []int
+func push(n int) State {
+	return Modify(func(st interface{}) interface{} {
+		return append(st.([]int), n)
+	})
+}
+
+var pop State = Get.Bind(func(st_ interface{}) State {
+	st := st_.([]int)
+	return Put(st[:len(st)-1]).ThenReturn(st[len(st)-1])
+})
+
+func binop(op func(a, b int) int) State {
+	return pop.Bind(func(b interface{}) State {
+		return pop.Bind(func(a interface{}) State {
+			return push(op(a.(int), b.(int)))
+		})
+	})
+}
+
+var add = binop(func(a, b int) int {return a + b})
+var mul = binop(func(a, b int) int {return a * b})
+
+func ExampleState() {
+	// (1 + 2) * 4
+	prog := push(1).Then(push(2)).Then(add).Then(push(4)).Then(mul).Then(pop)
+	x, st := prog([]int(nil))
+	fmt.Println(x, st)
+	// Output: 12 []
+}
+
+func ExampleModify() {
+	count := Modify(func(n interface{}) interface{} {return n.(int) + 1})
+	fmt.Println(count.Then(count).Then(count).Exec(0))
+	// Output: 3
+}
@@ -0,0 +1,220 @@
+package monad
+
+
+// monad transformers: each adds an effect to a base monad. since there is no
+// way to get at the Return of a Monad instance, it is passed in explicitly
+// as 'ret' where needed.
+
+
+// the result of a StateT computation, along with the new state
+type Pair struct {
+	Result interface{}
+	State  interface{}
+}
+
+// State over a base monad
+type StateT struct {
+	ret func(interface{}) Monad
+	run func(s interface{}) Monad	// yields a Pair
+}
+
+func ReturnStateT(ret func(interface{}) Monad, x interface{}) StateT {
+	return StateT{ret, func(s interface{}) Monad {
+		return ret(Pair{x, s})
+	}}
+}
+
+func LiftStateT(ret func(interface{}) Monad, m Monad) StateT {
+	return StateT{ret, func(s interface{}) Monad {
+		return m.Bind_(func(x interface{}) Monad {
+			return ret(Pair{x, s})
+		})
+	}}
+}
+
+func GetT(ret func(interface{}) Monad) StateT {
+	return StateT{ret, func(s interface{}) Monad {
+		return ret(Pair{s, s})
+	}}
+}
+
+func PutT(ret func(interface{}) Monad, s interface{}) StateT {
+	return StateT{ret, func(interface{}) Monad {
+		return ret(Pair{nil, s})
+	}}
+}
+
+func ModifyT(ret func(interface{}) Monad,
+             f func(interface{}) interface{}) StateT {
+	return StateT{ret, func(s interface{}) Monad {
+		return ret(Pair{nil, f(s)})
+	}}
+}
+
+// the base computation, yielding a Pair
+func (a StateT) Run(s interface{}) Monad {
+	return a.run(s)
+}
+
+func (a StateT) Bind(f func(interface{}) StateT) StateT {
+	return StateT{a.ret, func(s interface{}) Monad {
+		return a.run(s).Bind_(func(p_ interface{}) Monad {
+			p := p_.(Pair)
+			return f(p.Result).run(p.State)
+		})
+	}}
+}
+
+func (a StateT) Then(b StateT) StateT {
+	return a.Bind(func(interface{}) StateT {return b})
+}
+
+func (a StateT) ThenReturn(x interface{}) StateT {
+	return a.Then(ReturnStateT(a.ret, x))
+}
+
+func (a StateT) Then_(b_ Monad) Monad {
+	return a.Then(b_.(StateT))
+}
+
+func (a StateT) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a StateT) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) StateT {return f_(x).(StateT)}
+	return a.Bind(f)
+}
+
+
+// Reader over a base monad
+type ReaderT struct {
+	ret func(interface{}) Monad
+	run func(env interface{}) Monad
+}
+
+func ReturnReaderT(ret func(interface{}) Monad, x interface{}) ReaderT {
+	return ReaderT{ret, func(interface{}) Monad {
+		return ret(x)
+	}}
+}
+
+func LiftReaderT(ret func(interface{}) Monad, m Monad) ReaderT {
+	return ReaderT{ret, func(interface{}) Monad {
+		return m
+	}}
+}
+
+func AskT(ret func(interface{}) Monad) ReaderT {
+	return ReaderT{ret, ret}
+}
+
+// run a in the environment f(env)
+func LocalT(f func(interface{}) interface{}, a ReaderT) ReaderT {
+	return ReaderT{a.ret, func(env interface{}) Monad {
+		return a.run(f(env))
+	}}
+}
+
+func (a ReaderT) Run(env interface{}) Monad {
+	return a.run(env)
+}
+
+func (a ReaderT) Bind(f func(interface{}) ReaderT) ReaderT {
+	return ReaderT{a.ret, func(env interface{}) Monad {
+		return a.run(env).Bind_(func(x interface{}) Monad {
+			return f(x).run(env)
+		})
+	}}
+}
+
+func (a ReaderT) Then(b ReaderT) ReaderT {
+	return ReaderT{a.ret, func(env interface{}) Monad {
+		return a.run(env).Then_(b.run(env))
+	}}
+}
+
+func (a ReaderT) ThenReturn(x interface{}) ReaderT {
+	return a.Then(ReturnReaderT(a.ret, x))
+}
+
+func (a ReaderT) Then_(b_ Monad) Monad {
+	return a.Then(b_.(ReaderT))
+}
+
+func (a ReaderT) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a ReaderT) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) ReaderT {return f_(x).(ReaderT)}
+	return a.Bind(f)
+}
+
+
+// errors over a base monad; short-circuits like Result
+type ErrorT struct {
+	ret func(interface{}) Monad
+	m   Monad	// yields a Result
+}
+
+func ReturnErrorT(ret func(interface{}) Monad, x interface{}) ErrorT {
+	return ErrorT{ret, ret(Ok(x))}
+}
+
+func LiftErrorT(ret func(interface{}) Monad, m Monad) ErrorT {
+	return ErrorT{ret, m.Bind_(func(x interface{}) Monad {
+		return ret(Ok(x))
+	})}
+}
+
+func ThrowT(ret func(interface{}) Monad, err error) ErrorT {
+	return ErrorT{ret, ret(Err(err))}
+}
+
+// the base computation, yielding a Result
+func (a ErrorT) Run() Monad {
+	return a.m
+}
+
+// handle an error by continuing with f
+func (a ErrorT) Catch(f func(error) ErrorT) ErrorT {
+	return ErrorT{a.ret, a.m.Bind_(func(r_ interface{}) Monad {
+		r := r_.(Result)
+		if r.err == nil {
+			return a.ret(r)
+		}
+		return f(r.err).m
+	})}
+}
+
+func (a ErrorT) Bind(f func(interface{}) ErrorT) ErrorT {
+	return ErrorT{a.ret, a.m.Bind_(func(r_ interface{}) Monad {
+		r := r_.(Result)
+		if r.err != nil {
+			return a.ret(r)
+		}
+		return f(r.value).m
+	})}
+}
+
+func (a ErrorT) Then(b ErrorT) ErrorT {
+	return a.Bind(func(interface{}) ErrorT {return b})
+}
+
+func (a ErrorT) ThenReturn(x interface{}) ErrorT {
+	return a.Then(ReturnErrorT(a.ret, x))
+}
+
+func (a ErrorT) Then_(b_ Monad) Monad {
+	return a.Then(b_.(ErrorT))
+}
+
+func (a ErrorT) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a ErrorT) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) ErrorT {return f_(x).(ErrorT)}
+	return a.Bind(f)
+}
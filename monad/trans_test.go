@@ -0,0 +1,66 @@
+package monad
+
+import (
+	"errors"
+	"fmt"
+)
+
+
+// ErrorT over StateT over IO: a counter that gives up past a limit
+var ioRet = func(x interface{}) Monad {return ReturnIO(x)}
+var stRet = func(x interface{}) Monad {return ReturnStateT(ioRet, x)}
+
+var errLimit = errors.New("limit reached")
+
+func say(msg string) ErrorT {
+	return LiftErrorT(stRet, LiftStateT(ioRet, IO(func() interface{} {
+		fmt.Println(msg)
+		return nil
+	})))
+}
+
+var tick ErrorT = LiftErrorT(stRet, GetT(ioRet)).Bind(func(n interface{}) ErrorT {
+	if n.(int) >= 2 {
+		return ThrowT(stRet, errLimit)
+	}
+	return LiftErrorT(stRet, PutT(ioRet, n.(int) + 1)).
+	       Then(say(fmt.Sprint("tick ", n)))
+})
+
+func ExampleErrorT() {
+	prog := tick.Then(tick).Then(tick).Then(say("not reached"))
+	p := prog.Run().(StateT).Run(0).(IO)().(Pair)
+	fmt.Println(p.Result.(Result).Get())
+	fmt.Println(p.State)
+
+	// the state survives the error
+	prog = prog.Catch(func(err error) ErrorT {
+		return say("caught: " + err.Error()).ThenReturn("ok")
+	})
+	p = prog.Run().(StateT).Run(1).(IO)().(Pair)
+	fmt.Println(p.Result.(Result).Get())
+	fmt.Println(p.State)
+	// Output:
+	// tick 0
+	// tick 1
+	// <nil> limit reached
+	// 2
+	// tick 1
+	// caught: limit reached
+	// ok <nil>
+	// 2
+}
+
+func ExampleReaderT() {
+	greet := AskT(ioRet).Bind(func(name interface{}) ReaderT {
+		return LiftReaderT(ioRet, IO(func() interface{} {
+			fmt.Println("hello,", name)
+			return nil
+		}))
+	})
+	prog := greet.Then(LocalT(func(interface{}) interface{} {return "world"}, greet))
+	prog.Run("gopher").(IO)()
+	// Output:
+	// hello, gopher
+	// hello, world
+}
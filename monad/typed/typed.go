@@ -0,0 +1,71 @@
+// a type-parameterized counterpart to package monad. without a way to name
+// the instance type in an interface, Bind and friends are top-level generic
+// functions rather than methods; in return, mixing up the types of actions
+// is caught at compile time instead of by a failed assertion at run time.
+//
+// the names IO and Return would clash with the existing ones, hence the
+// separate package.
+package typed
+
+import "github.com/pesco/go/monad"
+
+
+type IO[A any] func() A
+
+func Return[A any](x A) IO[A] {
+	return func() A {
+		return x
+	}
+}
+
+func Bind[A, B any](a IO[A], f func(A) IO[B]) IO[B] {
+	return func() B {
+		return f(a())()
+	}
+}
+
+func Then[A, B any](a IO[A], b IO[B]) IO[B] {
+	return func() B {
+		a(); return b()
+	}
+}
+
+func ThenReturn[A, B any](a IO[A], x B) IO[B] {
+	return func() B {
+		a(); return x
+	}
+}
+
+func Map[A, B any](a IO[A], f func(A) B) IO[B] {
+	return func() B {
+		return f(a())
+	}
+}
+
+
+// adapters...
+
+// the untyped equivalent of a
+func (a IO[A]) Untyped() monad.IO {
+	return func() interface{} {
+		return a()
+	}
+}
+
+// a typed view of m. asserts the result of m to A when run, so this is the
+// one place a mismatch can still panic. a nil result becomes the zero A.
+func FromIO[A any](m monad.IO) IO[A] {
+	return func() A {
+		x := m()
+		if x == nil {
+			var zero A
+			return zero
+		}
+		return x.(A)
+	}
+}
+
+// as FromIO, for a Monad that must be a monad.IO
+func FromMonad[A any](m monad.Monad) IO[A] {
+	return FromIO[A](m.(monad.IO))
+}
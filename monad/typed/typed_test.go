@@ -0,0 +1,69 @@
+package typed
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pesco/go/monad"
+)
+
+
+func print(s string) IO[int] {
+	return func() int {
+		n, _ := fmt.Print(s)
+		return n
+	}
+}
+
+func ExampleBind() {
+	prog := Bind(print("hallo "), func(n int) IO[string] {
+		return ThenReturn(print("welt\n"), strconv.Itoa(n))
+	})
+	fmt.Printf("%q\n", prog())
+	// Output:
+	// hallo welt
+	// "6"
+}
+
+func ExampleMap() {
+	length := Map(Return("hallo"), func(s string) int {return len(s)})
+	fmt.Println(length() + 1)
+	// Output: 6
+}
+
+func ExampleFromIO() {
+	// an untyped action, used in typed code and handed back again
+	var untyped monad.IO = monad.ReturnIO(21)
+	double := Map(FromIO[int](untyped), func(x int) int {return 2 * x})
+	var m monad.Monad = Then(print("answer: "), double).Untyped()
+	fmt.Println(m.(monad.IO)())
+	// Output: answer: 42
+}
+
+func TestAdapters(t *testing.T) {
+	log := ""
+	a := IO[string](func() string {log += "a"; return "x"})
+	if x := FromMonad[string](a.Untyped())(); x != "x" || log != "a" {
+		t.Error("wrong result; got:", x, log)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("mismatched type should have panicked")
+		}
+	}()
+	FromIO[int](a.Untyped())()
+}
+
+func TestFromIONil(t *testing.T) {
+	if x := FromIO[any](monad.ReturnIO(nil))(); x != nil {
+		t.Error("wrong result; got:", x)
+	}
+	if x := FromIO[error](monad.ReturnIO(nil))(); x != nil {
+		t.Error("wrong result; got:", x)
+	}
+	if x := FromIO[int](monad.ReturnIO(nil))(); x != 0 {
+		t.Error("wrong result; got:", x)
+	}
+}
@@ -0,0 +1,137 @@
+package monad
+
+
+// generic helpers for any Monad instance. those that need to produce a value
+// out of thin air take the instance's Return as 'ret'. the results are of the
+// instance type, so they can be asserted back, e.g. Sequence(...).(IO).
+
+
+// run the given actions in order, yielding their results as a []interface{}
+func Sequence(ret func(interface{}) Monad, ms ...Monad) Monad {
+	if len(ms) == 0 {
+		return ret([]interface{}(nil))
+	}
+	return sequence(ret, 0, ms)
+}
+
+// the result slice is allocated at the end, so every run gets its own
+func sequence(ret func(interface{}) Monad, i int, ms []Monad) Monad {
+	if i >= len(ms) {
+		return ret(make([]interface{}, len(ms)))
+	}
+	return ms[i].Bind_(func(x interface{}) Monad {
+		return sequence(ret, i+1, ms).Bind_(func(sl interface{}) Monad {
+			sl.([]interface{})[i] = x
+			return ret(sl)
+		})
+	})
+}
+
+// discards results
+func Sequence_(ret func(interface{}) Monad, ms ...Monad) Monad {
+	if len(ms) == 0 {
+		return ret(nil)
+	}
+	return ms[0].Bind_(func(interface{}) Monad {
+		return Sequence_(ret, ms[1:]...)
+	})
+}
+
+func MapM(ret func(interface{}) Monad,
+          f func(interface{}) Monad, xs []interface{}) Monad {
+	ms := make([]Monad, len(xs))
+	for i, x := range xs {
+		ms[i] = f(x)
+	}
+	return Sequence(ret, ms...)
+}
+
+// MapM with the arguments flipped
+func ForM(ret func(interface{}) Monad,
+          xs []interface{}, f func(interface{}) Monad) Monad {
+	return MapM(ret, f, xs)
+}
+
+// left fold; f is given the accumulator and the next element
+func FoldM(ret func(interface{}) Monad,
+           f func(acc, x interface{}) Monad, z interface{},
+           xs []interface{}) Monad {
+	if len(xs) == 0 {
+		return ret(z)
+	}
+	return f(z, xs[0]).Bind_(func(acc interface{}) Monad {
+		return FoldM(ret, f, acc, xs[1:])
+	})
+}
+
+// stops at the end of the shorter slice
+func ZipWithM(ret func(interface{}) Monad,
+              f func(x, y interface{}) Monad, xs, ys []interface{}) Monad {
+	n := len(xs)
+	if len(ys) < n {
+		n = len(ys)
+	}
+	ms := make([]Monad, n)
+	for i := 0; i < n; i++ {
+		ms[i] = f(xs[i], ys[i])
+	}
+	return Sequence(ret, ms...)
+}
+
+// run m n times, yielding the results as a []interface{}
+func Replicate(ret func(interface{}) Monad, n int, m Monad) Monad {
+	ms := make([]Monad, n)
+	for i := range ms {
+		ms[i] = m
+	}
+	return Sequence(ret, ms...)
+}
+
+// m if cond holds, Return(nil) otherwise
+func When(ret func(interface{}) Monad, cond bool, m Monad) Monad {
+	if cond {
+		return m
+	}
+	return ret(nil)
+}
+
+func Unless(ret func(interface{}) Monad, cond bool, m Monad) Monad {
+	return When(ret, !cond, m)
+}
+
+// repeat m indefinitely. only ends if the instance can short-circuit, e.g.
+// an iteratee failing at the end of input.
+//
+// NB: every round nests another Bind, so for instances that run their binds
+// on the stack, like IO and IOResult, the stack grows with each round until
+// the runtime gives up. for long-running loops, use their Forever methods.
+func Forever(m Monad) Monad {
+	return m.Bind_(func(interface{}) Monad {
+		return Forever(m)
+	})
+}
+
+// flatten a monad yielding monads of the same instance
+func Join(m Monad) Monad {
+	return m.Bind_(func(x interface{}) Monad {
+		return x.(Monad)
+	})
+}
+
+// apply the function yielded by mf to the value yielded by mx
+func Ap(ret func(interface{}) Monad, mf, mx Monad) Monad {
+	return mf.Bind_(func(f interface{}) Monad {
+		return mx.Bind_(func(x interface{}) Monad {
+			return ret(f.(func(interface{}) interface{})(x))
+		})
+	})
+}
+
+func LiftA2(ret func(interface{}) Monad,
+            f func(x, y interface{}) interface{}, a, b Monad) Monad {
+	return a.Bind_(func(x interface{}) Monad {
+		return b.Bind_(func(y interface{}) Monad {
+			return ret(f(x, y))
+		})
+	})
+}
@@ -0,0 +1,146 @@
+package monad
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+
+var retIO = func(x interface{}) Monad {return ReturnIO(x)}
+var retResult = func(x interface{}) Monad {return Ok(x)}
+var retList = func(x interface{}) Monad {return ReturnList(x)}
+
+func ExampleSequence() {
+	m := Sequence(retIO, hallo, print("schöne "), welt).(IO)
+	fmt.Printf("%q\n", m())
+	// Output:
+	// hallo schöne welt
+	// ["hallo " "schöne " "welt\n"]
+}
+
+func ExampleMapM() {
+	half := func(x interface{}) Monad {
+		if x.(int) % 2 != 0 {
+			return Err(fmt.Errorf("%d is odd", x))
+		}
+		return Ok(x.(int) / 2)
+	}
+	fmt.Println(MapM(retResult, half, []interface{}{2, 4, 6}).(Result).Get())
+	fmt.Println(MapM(retResult, half, []interface{}{2, 3, 6}).(Result).Get())
+	// Output:
+	// [1 2 3] <nil>
+	// <nil> 3 is odd
+}
+
+func ExampleFoldM() {
+	sum := func(acc, x interface{}) Monad {
+		return print(fmt.Sprint(x, " ")).ThenReturn(acc.(int) + x.(int))
+	}
+	fmt.Println(FoldM(retIO, sum, 0, []interface{}{1, 2, 3}).(IO)())
+	// Output: 1 2 3 6
+}
+
+func ExampleWhen() {
+	verbose := true
+	When(retIO, verbose, hallo).(IO)()
+	Unless(retIO, verbose, welt).(IO)()
+	// Output: hallo
+}
+
+func ExampleLiftA2() {
+	add := func(x, y interface{}) interface{} {return x.(int) + y.(int)}
+	fmt.Println(LiftA2(retResult, add, Ok(1), Ok(2)))
+	fmt.Println(LiftA2(retList, add, List{1, 2}, List{10, 20}))
+	// Output:
+	// {3 <nil>}
+	// [11 21 12 22]
+}
+
+func TestSequenceFresh(t *testing.T) {
+	// every run must get its own result slice
+	m := Sequence(retIO, ReturnIO(1), ReturnIO(2)).(IO)
+	a := m().([]interface{})
+	b := m().([]interface{})
+	a[0] = 0
+	if b[0] != 1 {
+		t.Error("result slice shared between runs")
+	}
+}
+
+func TestReplicate(t *testing.T) {
+	n := 0
+	count := IO(func() interface{} {n++; return n})
+	r := Replicate(retIO, 3, count).(IO)().([]interface{})
+	if fmt.Sprint(r) != "[1 2 3]" {
+		t.Error("wrong result; got:", r)
+	}
+	if r := Replicate(retIO, 0, count).(IO)(); len(r.([]interface{})) != 0 {
+		t.Error("wrong result; got:", r)
+	}
+}
+
+func TestZipWithM(t *testing.T) {
+	pair := func(x, y interface{}) Monad {return Just(fmt.Sprint(x, y))}
+	xs := []interface{}{1, 2, 3}
+	ys := []interface{}{"a", "b"}
+	r, _ := ZipWithM(func(x interface{}) Monad {return Just(x)},
+	                 pair, xs, ys).(Maybe).Get()
+	if fmt.Sprint(r) != "[1a 2b]" {
+		t.Error("wrong result; got:", r)
+	}
+}
+
+func TestForever(t *testing.T) {
+	stop := errors.New("stop")
+	n := 0
+	step := IOResult(func() Result {
+		if n++; n == 5 {
+			return Err(stop)
+		}
+		return Ok(n)
+	})
+	if r := Forever(step).(IOResult)(); r != Err(stop) || n != 5 {
+		t.Error("wrong result; got:", r, n)
+	}
+}
+
+func TestForeverMethods(t *testing.T) {
+	// enough rounds to exhaust the stack if each one nested
+	stop := errors.New("stop")
+	n := 0
+	step := IOResult(func() Result {
+		if n++; n == 10000000 {
+			return Err(stop)
+		}
+		return Ok(n)
+	})
+	if r := step.Forever()(); r != Err(stop) {
+		t.Error("wrong result; got:", r)
+	}
+
+	n = 0
+	func() {
+		defer func() {
+			if p := recover(); p != stop {
+				t.Error("wrong panic; got:", p)
+			}
+		}()
+		IO(func() interface{} {
+			if n++; n == 10000000 {
+				panic(stop)
+			}
+			return n
+		}).Forever()()
+	}()
+}
+
+func TestJoinAp(t *testing.T) {
+	if r := Join(Just(Just(1))); r != Just(1) {
+		t.Error("Join: wrong result; got:", r)
+	}
+	inc := func(x interface{}) interface{} {return x.(int) + 1}
+	if r := Ap(retResult, Ok(inc), Ok(1)); r != Ok(2) {
+		t.Error("Ap: wrong result; got:", r)
+	}
+}
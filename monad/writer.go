@@ -0,0 +1,72 @@
+package monad
+
+
+// an associative operation whose identity is nil, e.g. concatenation
+type Monoid interface {
+	Append(Monoid) Monoid
+}
+
+func mappend(a, b Monoid) Monoid {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return a.Append(b)
+}
+
+// a Monoid of log lines
+type Lines []string
+
+func (a Lines) Append(b_ Monoid) Monoid {
+	b := b_.(Lines)
+	return append(a[:len(a):len(a)], b...)
+}
+
+
+// a value along with a log accumulated in a Monoid
+type Writer struct {
+	value interface{}
+	log   Monoid
+}
+
+func ReturnWriter(x interface{}) Writer {
+	return Writer{x, nil}
+}
+
+// add w to the log
+func Tell(w Monoid) Writer {
+	return Writer{nil, w}
+}
+
+func (a Writer) Run() (interface{}, Monoid) {return a.value, a.log}
+
+
+// monad instance...
+
+func (a Writer) Bind(f func(interface{}) Writer) Writer {
+	b := f(a.value)
+	return Writer{b.value, mappend(a.log, b.log)}
+}
+
+func (a Writer) Then(b Writer) Writer {
+	return Writer{b.value, mappend(a.log, b.log)}
+}
+
+func (a Writer) ThenReturn(x interface{}) Writer {
+	return a.Then(ReturnWriter(x))
+}
+
+func (a Writer) Then_(b_ Monad) Monad {
+	return a.Then(b_.(Writer))
+}
+
+func (a Writer) ThenReturn_(x interface{}) Monad {
+	return a.ThenReturn(x)
+}
+
+func (a Writer) Bind_(f_ func(interface{}) Monad) Monad {
+	f := func(x interface{}) Writer {return f_(x).(Writer)}
+	return a.Bind(f)
+}
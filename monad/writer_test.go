@@ -0,0 +1,21 @@
+package monad
+
+import "fmt"
+
+
+func logged(msg string, x interface{}) Writer {
+	return Tell(Lines{msg}).ThenReturn(x)
+}
+
+func ExampleWriter() {
+	double := func(x interface{}) Writer {
+		return logged(fmt.Sprint("doubling ", x), x.(int) * 2)
+	}
+
+	x, log := logged("start", 5).Bind(double).Bind(double).Run()
+	fmt.Println(x)
+	fmt.Printf("%q\n", log)
+	// Output:
+	// 20
+	// ["start" "doubling 5" "doubling 10"]
+}